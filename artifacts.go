@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// artifacts collects the files of one run under -output-dir: the JSON
+// report and latency histogram written by the manager, plus the accepted
+// hashes, the receipts and the signed transactions recorded from its hooks.
+// Every name starts with the run label and start time, so later runs into
+// the same directory do not clobber earlier ones.
+type artifacts struct {
+	prefix string
+
+	mu       sync.Mutex
+	hashes   *os.File
+	receipts *os.File
+	txs      *os.File
+	encoder  *json.Encoder
+	csv      *csv.Writer
+}
+
+// openArtifacts creates dir if needed and the hash, receipt and transaction
+// files of a run labelled label starting at start.
+func openArtifacts(dir, label string, start time.Time) (*artifacts, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	prefix := start.UTC().Format("20060102T150405Z") + "-"
+	if label != "" {
+		prefix = fileSafe(label) + "-" + prefix
+	}
+	a := &artifacts{prefix: filepath.Join(dir, prefix)}
+
+	var err error
+	if a.hashes, err = a.create("hashes.txt"); err != nil {
+		return nil, err
+	}
+	if a.receipts, err = a.create("receipts.jsonl"); err != nil {
+		a.Close()
+		return nil, err
+	}
+	if a.txs, err = a.create("txs.csv"); err != nil {
+		a.Close()
+		return nil, err
+	}
+	a.encoder = json.NewEncoder(a.receipts)
+	a.csv = csv.NewWriter(a.txs)
+	a.csv.Write([]string{"hash", "from", "nonce", "to", "value", "gas", "gas_fee_cap", "gas_tip_cap", "type"})
+	return a, nil
+}
+
+// Path returns where the artifact called name is written.
+func (a *artifacts) Path(name string) string {
+	return a.prefix + name
+}
+
+func (a *artifacts) create(name string) (*os.File, error) {
+	f, err := os.Create(a.Path(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	return f, nil
+}
+
+// Hook wraps the manager's callbacks to also record to the files; any of
+// them may be nil.
+func (a *artifacts) Hook(onSigned func(*types.Transaction), onBroadcast func(common.Hash, error), onConfirmed func(*types.Receipt)) (func(*types.Transaction), func(common.Hash, error), func(*types.Receipt)) {
+	signed := func(tx *types.Transaction) {
+		a.signed(tx)
+		if onSigned != nil {
+			onSigned(tx)
+		}
+	}
+	broadcast := func(hash common.Hash, err error) {
+		if err == nil {
+			a.accepted(hash)
+		}
+		if onBroadcast != nil {
+			onBroadcast(hash, err)
+		}
+	}
+	confirmed := func(receipt *types.Receipt) {
+		a.confirmed(receipt)
+		if onConfirmed != nil {
+			onConfirmed(receipt)
+		}
+	}
+	return signed, broadcast, confirmed
+}
+
+// signed adds a row to txs.csv.
+func (a *artifacts) signed(tx *types.Transaction) {
+	var from, to string
+	signer := types.LatestSignerForChainID(tx.ChainId())
+	if !tx.Protected() {
+		signer = types.HomesteadSigner{}
+	}
+	if sender, err := types.Sender(signer, tx); err == nil {
+		from = sender.Hex()
+	}
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.csv.Write([]string{
+		tx.Hash().Hex(),
+		from,
+		strconv.FormatUint(tx.Nonce(), 10),
+		to,
+		tx.Value().String(),
+		strconv.FormatUint(tx.Gas(), 10),
+		tx.GasFeeCap().String(),
+		tx.GasTipCap().String(),
+		strconv.Itoa(int(tx.Type())),
+	})
+}
+
+// accepted adds a line to hashes.txt.
+func (a *artifacts) accepted(hash common.Hash) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	fmt.Fprintln(a.hashes, hash.Hex())
+}
+
+// confirmed adds a line to receipts.jsonl.
+func (a *artifacts) confirmed(receipt *types.Receipt) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.encoder.Encode(receipt)
+}
+
+// Close flushes and closes the files, returning the first error.
+func (a *artifacts) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs []error
+	if a.csv != nil {
+		a.csv.Flush()
+		errs = append(errs, a.csv.Error())
+	}
+	for _, f := range []*os.File{a.hashes, a.receipts, a.txs} {
+		if f != nil {
+			errs = append(errs, f.Close())
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("failed to write artifacts: %w", err)
+		}
+	}
+	return nil
+}
+
+// fileSafe replaces the characters of s that do not belong in a file name.
+func fileSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, s)
+}
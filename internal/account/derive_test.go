@@ -0,0 +1,78 @@
+package ethwallet
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+const (
+	testMnemonic = "test test test test test test test test test test test junk"
+	// benchmarkWallets is how many wallets every derivation benchmark derives.
+	benchmarkWallets = 512
+)
+
+// liveHeap returns the bytes of heap still reachable after a collection.
+func liveHeap() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapAlloc)
+}
+
+// BenchmarkDeriveWallets compares the memory of deriving every wallet into a
+// slice with streaming them through DeriveWalletsChan to a reader that keeps
+// none. Besides the allocations, retained-B/op is the heap the wallets still
+// hold once all of them are derived, measured on one more pass with the
+// timer stopped: the slice keeps every wallet, the stream only the few
+// buffered ahead of its reader while it runs.
+func BenchmarkDeriveWallets(b *testing.B) {
+	client := rpc.NewNullBackend()
+	slice := func() []*WalletInfo {
+		wallets, err := DeriveEthereumWalletsFromMnemonic(testMnemonic, DerivationPath{}, benchmarkWallets, client, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return wallets
+	}
+	stream := func() {
+		wallets, errs := DeriveWalletsChan(context.Background(), testMnemonic, DerivationPath{}, benchmarkWallets, client, 0)
+		read := 0
+		for range wallets {
+			read++
+		}
+		if err := <-errs; err != nil {
+			b.Fatal(err)
+		}
+		if read != benchmarkWallets {
+			b.Fatalf("streamed %d wallets, want %d", read, benchmarkWallets)
+		}
+	}
+	// Load the curve tables every derivation shares before measuring.
+	slice()
+
+	b.Run("slice", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			slice()
+		}
+		b.StopTimer()
+		base := liveHeap()
+		wallets := slice()
+		b.ReportMetric(float64(max(liveHeap()-base, 0)), "retained-B/op")
+		runtime.KeepAlive(wallets)
+	})
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			stream()
+		}
+		b.StopTimer()
+		base := liveHeap()
+		stream()
+		b.ReportMetric(float64(max(liveHeap()-base, 0)), "retained-B/op")
+	})
+}
@@ -0,0 +1,69 @@
+package ethwallet
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInsufficientFunds is reported when a wallet cannot pay value + gas for a transaction.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrMempoolFull is reported when the node's transaction pool, or the share of
+// it one account may use, has no room for another transaction.
+var ErrMempoolFull = errors.New("mempool full")
+
+// ErrAlreadyKnown is reported when the node already holds the transaction in its pool.
+var ErrAlreadyKnown = errors.New("already known")
+
+// ErrNonceTooLow is reported when the node already has a transaction of the
+// account at the nonce, or a later one, so the transaction can never be mined.
+var ErrNonceTooLow = errors.New("nonce too low")
+
+// SendError is a broadcast error recognized as one of the typed errors above.
+// errors.Is matches both the typed error and the original node error.
+type SendError struct {
+	Kind error
+	Err  error
+}
+
+func (e *SendError) Error() string { return e.Err.Error() }
+
+func (e *SendError) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// sendErrorPatterns maps lowercase fragments of node error messages to typed errors.
+// Nodes only return JSON-RPC error strings, so matching text is the only option.
+var sendErrorPatterns = []struct {
+	fragment string
+	kind     error
+}{
+	{"insufficient funds", ErrInsufficientFunds},
+	{"already known", ErrAlreadyKnown},
+	{"nonce too low", ErrNonceTooLow},
+	{"txpool is full", ErrMempoolFull},
+	{"transaction pool is full", ErrMempoolFull},
+	{"txpool full", ErrMempoolFull},
+	{"too many pending", ErrMempoolFull},
+	{"account limit exceeded", ErrMempoolFull},
+	{"exceeds the per-account limit", ErrMempoolFull},
+	{"pending queue is full", ErrMempoolFull},
+}
+
+// ClassifySendError wraps err in a *SendError when its message matches a known
+// failure; other errors, including nil, are returned unchanged.
+func ClassifySendError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, p := range sendErrorPatterns {
+		if strings.Contains(msg, p.fragment) {
+			return &SendError{Kind: p.kind, Err: err}
+		}
+	}
+	return err
+}
+
+// ErrNilChainID is returned when asked to sign without a chain ID: the
+// signature would be invalid on every chain.
+var ErrNilChainID = errors.New("cannot sign transaction: chain ID is nil")
@@ -1,49 +1,134 @@
 package ethwallet
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 	"github.com/tyler-smith/go-bip39"
 )
 
+// TransferValue is the amount of Wei each self-transfer carries.
+const TransferValue = 10000
+
+// GasBuffer is the gas added to every estimate when no GasMultiplier is set.
+const GasBuffer = 1000
+
+// DerivationPathFormat is the BIP-44 path wallets are derived at, filled in
+// with the account and change levels of a DerivationPath and the wallet index.
+const DerivationPathFormat = "m/44'/60'/%d'/%d/%d"
+
+// DerivationPath selects the account and change levels of the BIP-44 path
+// m/44'/60'/{Account}'/{Change}/{i} that wallet i is derived at. The zero
+// value is the path most wallets use, m/44'/60'/0'/0/{i}; a different
+// account or change level gives a wholly different set of addresses.
+type DerivationPath struct {
+	Account uint32
+	Change  uint32
+}
+
+// At returns the path of the wallet at index.
+func (p DerivationPath) At(index int) string {
+	return fmt.Sprintf(DerivationPathFormat, p.Account, p.Change, index)
+}
+
+// String returns the path with the wallet index left as {i}.
+func (p DerivationPath) String() string {
+	return fmt.Sprintf("m/44'/60'/%d'/%d/{i}", p.Account, p.Change)
+}
+
+// hardenedOffset is added to a level to harden it, so levels must stay below it.
+const hardenedOffset = 1 << 31
+
+// Validate checks that both levels fit below the hardened offset: the
+// account level is hardened by the path and the change level is not.
+func (p DerivationPath) Validate() error {
+	if p.Account >= hardenedOffset {
+		return fmt.Errorf("account index %d must be < %d", p.Account, hardenedOffset)
+	}
+	if p.Change >= hardenedOffset {
+		return fmt.Errorf("change index %d must be < %d", p.Change, hardenedOffset)
+	}
+	return nil
+}
+
+// ValidateMnemonic returns an error unless mnemonic is a valid BIP-39 phrase.
+func ValidateMnemonic(mnemonic string) error {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return fmt.Errorf("invalid mnemonic")
+	}
+	return nil
+}
+
+// ValidateDerivationPaths checks that the derivation paths of the first `count` wallets under path parse.
+func ValidateDerivationPaths(path DerivationPath, count int) error {
+	if count <= 0 {
+		return errors.New("count must be > 0")
+	}
+	if err := path.Validate(); err != nil {
+		return err
+	}
+	// Paths only differ by the trailing index, so the widest one is enough.
+	derivationPath := path.At(count - 1)
+	if _, err := hdwallet.ParseDerivationPath(derivationPath); err != nil {
+		return fmt.Errorf("failed to parse derivation path %s: %w", derivationPath, err)
+	}
+	return nil
+}
+
 // WalletInfo holds the derived address and private key hex string.
 type WalletInfo struct {
 	Address    common.Address    // Hex address, e.g., "0x..."
+	Label      string            // Optional name shown in logs and reports instead of the address
 	PrivateKey *ecdsa.PrivateKey // Hex of the private key (64 bytes hex, without 0x prefix)
-	Client     *ethclient.Client
+	Client     rpc.EthBackend
 	mu         *sync.Mutex
 	WaitMilis  int
 	Failed     int
 	Success    int
 }
 
+// Name returns the wallet's Label, or its address when it has none.
+func (wallet *WalletInfo) Name() string {
+	if wallet.Label != "" {
+		return wallet.Label
+	}
+	return wallet.Address.Hex()
+}
+
 // DeriveEthereumWalletsFromMnemonic derives `count` Ethereum wallets from the given mnemonic and optional passphrase.
 //
 // mnemonic: BIP-39 mnemonic phrase (12/15/18/21/24 words).
 // passphrase: optional passphrase for the seed; often "" if not used.
+// path: the account and change levels of the derivation path.
 // count: how many addresses to derive starting at index 0.
 //
 // Returns a slice of WalletInfo of length `count`, or an error.
-func DeriveEthereumWalletsFromMnemonic(mnemonic string, count int, client *ethclient.Client, waitMilis int) ([]*WalletInfo, error) {
+func DeriveEthereumWalletsFromMnemonic(mnemonic string, path DerivationPath, count int, client rpc.EthBackend, waitMilis int) ([]*WalletInfo, error) {
 	if count <= 0 {
 		return nil, errors.New("count must be > 0")
 	}
 
 	// 1. Validate mnemonic
-	if !bip39.IsMnemonicValid(mnemonic) {
-		return nil, fmt.Errorf("invalid mnemonic")
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
 	}
 
 	// 2. Create HD wallet
@@ -55,39 +140,115 @@ func DeriveEthereumWalletsFromMnemonic(mnemonic string, count int, client *ethcl
 
 	wallets := make([]*WalletInfo, 0, count)
 
-	// 3. For each index, derive path m/44'/60'/0'/0/i
+	// 3. For each index, derive path m/44'/60'/{account}'/{change}/i and its private key
 	for i := 0; i < count; i++ {
-		// Format the derivation path
-		// Example path: m/44'/60'/0'/0/0, m/44'/60'/0'/0/1, etc.
-		derivationPath := fmt.Sprintf("m/44'/60'/0'/0/%d", i)
-		path, err := hdwallet.ParseDerivationPath(derivationPath)
+		info, err := deriveWallet(wallet, path, i, client, waitMilis)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse derivation path %s: %w", derivationPath, err)
+			return nil, err
 		}
+		wallets = append(wallets, info)
+	}
 
-		account, err := wallet.Derive(path, false)
-		if err != nil {
-			return nil, fmt.Errorf("failed to derive account at index %d: %w", i, err)
-		}
+	return wallets, nil
+}
+
+// deriveWallet derives the wallet at BIP-44 index i of hd under path.
+func deriveWallet(hd *hdwallet.Wallet, path DerivationPath, i int, client rpc.EthBackend, waitMilis int) (*WalletInfo, error) {
+	// Format the derivation path
+	// Example path: m/44'/60'/0'/0/0, m/44'/60'/0'/0/1, etc.
+	derivationPath := path.At(i)
+	parsed, err := hdwallet.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse derivation path %s: %w", derivationPath, err)
+	}
+
+	account, err := hd.Derive(parsed, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive account at index %d: %w", i, err)
+	}
+
+	// Get the private key for this account
+	privKey, err := hd.PrivateKey(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private key for index %d: %w", i, err)
+	}
+
+	return &WalletInfo{
+		Address:    account.Address,
+		PrivateKey: privKey,
+		Client:     client,
+		mu:         &sync.Mutex{},
+		Failed:     0,
+		Success:    0,
+		WaitMilis:  waitMilis,
+	}, nil
+}
 
-		// 4. Get the private key for this account
-		privKey, err := wallet.PrivateKey(account)
+// WalletFromHexKey builds a single wallet from a hex-encoded secp256k1 private
+// key, with or without a 0x prefix, instead of deriving it from a mnemonic.
+func WalletFromHexKey(hexKey string, client rpc.EthBackend, waitMilis int) (*WalletInfo, error) {
+	hexKey = strings.TrimSpace(hexKey)
+	hexKey = strings.TrimPrefix(strings.TrimPrefix(hexKey, "0x"), "0X")
+	privKey, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	return &WalletInfo{
+		Address:    crypto.PubkeyToAddress(privKey.PublicKey),
+		PrivateKey: privKey,
+		Client:     client,
+		mu:         &sync.Mutex{},
+		WaitMilis:  waitMilis,
+	}, nil
+}
+
+// walletStreamBuffer is how many wallets DeriveWalletsChan derives ahead of its reader.
+const walletStreamBuffer = 64
+
+// DeriveWalletsChan derives the same wallets as DeriveEthereumWalletsFromMnemonic,
+// in index order, but streams them: only a few are held in memory at once, and
+// the reader can put early wallets to work while later ones are derived.
+//
+// Both channels are closed when derivation ends. The error channel then yields
+// the error that stopped it early, if any; cancelling ctx stops it silently.
+func DeriveWalletsChan(ctx context.Context, mnemonic string, path DerivationPath, count int, client rpc.EthBackend, waitMilis int) (<-chan *WalletInfo, <-chan error) {
+	wallets := make(chan *WalletInfo, walletStreamBuffer)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(wallets)
+
+		if count <= 0 {
+			errs <- errors.New("count must be > 0")
+			return
+		}
+		if err := ValidateMnemonic(mnemonic); err != nil {
+			errs <- err
+			return
+		}
+		hd, err := hdwallet.NewFromMnemonic(mnemonic)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get private key for index %d: %w", i, err)
+			errs <- fmt.Errorf("failed to create wallet from mnemonic: %w", err)
+			return
 		}
 
-		wallets = append(wallets, &WalletInfo{
-			Address:    account.Address,
-			PrivateKey: privKey,
-			Client:     client,
-			mu:         &sync.Mutex{},
-			Failed:     0,
-			Success:    0,
-			WaitMilis:  waitMilis,
-		})
-	}
+		for i := 0; i < count; i++ {
+			info, err := deriveWallet(hd, path, i, client, waitMilis)
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case wallets <- info:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
-	return wallets, nil
+	return wallets, errs
 }
 
 // GetBalanceWei connects to the given Ethereum JSON-RPC endpoint (rpcURL),
@@ -123,15 +284,105 @@ func GetBalanceWei(rpcURL, addressHex string) (*big.Int, error) {
 	return balanceWei, nil
 }
 
+// GetBalanceAtBlock returns the balance in Wei of addr at blockNumber using an
+// already connected client. blockNumber nil means the latest block; see
+// rpc.ParseBlockNumber for turning "latest", "pending" or a number into it.
+func GetBalanceAtBlock(client rpc.EthBackend, addr common.Address, blockNumber *big.Int) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	balanceWei, err := client.BalanceAt(ctx, addr, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance for address %s: %w", addr.Hex(), err)
+	}
+
+	return balanceWei, nil
+}
+
+// nativeDecimals is the number of decimal places of the chain's native token:
+// one Ether (or its counterpart) is 10^nativeDecimals Wei.
+var nativeDecimals = 18
+
+// SetNativeDecimals sets the decimals of the native token for WeiToEther,
+// EtherToWei and FormatEther, on custom chains whose token does not have 18.
+// Call it once at startup, before any amount is converted.
+func SetNativeDecimals(decimals int) error {
+	// A uint256 amount has at most 78 digits.
+	if decimals < 0 || decimals > 77 {
+		return fmt.Errorf("native decimals must be between 0 and 77, got %d", decimals)
+	}
+	nativeDecimals = decimals
+	return nil
+}
+
+// NativeDecimals returns the decimals of the native token (18 by default).
+func NativeDecimals() int {
+	return nativeDecimals
+}
+
 // WeiToEther converts a balance in Wei (*big.Int) to Ether (*big.Float).
-// It returns a *big.Float representing balance / 1e18.
-// Note: big.Float uses arbitrary precision; here we set sufficient precision for Ethereum values.
+// It returns a *big.Float representing balance / 10^NativeDecimals().
 func WeiToEther(balanceWei *big.Int) *big.Float {
-	// Create a big.Float from the Wei
-	fWei := new(big.Float).SetInt(balanceWei)
-	// Divide by 1e18
-	ethValue := new(big.Float).Quo(fWei, big.NewFloat(1e18))
-	return ethValue
+	return WeiToUnits(balanceWei, nativeDecimals)
+}
+
+// WeiToUnits converts an amount in Wei to a unit worth 10^decimals Wei.
+// Note: big.Float uses arbitrary precision; here we set sufficient precision for Ethereum values.
+func WeiToUnits(amountWei *big.Int, decimals int) *big.Float {
+	fWei := new(big.Float).SetInt(amountWei)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Float).Quo(fWei, new(big.Float).SetInt(scale))
+}
+
+// WeiToGwei converts an amount in Wei to Gwei (amount / 1e9).
+func WeiToGwei(amountWei *big.Int) *big.Float {
+	fWei := new(big.Float).SetInt(amountWei)
+	return new(big.Float).Quo(fWei, big.NewFloat(1e9))
+}
+
+// EtherToWei converts a decimal Ether amount such as "0.01" to Wei.
+// The conversion is exact; amounts with more than NativeDecimals() decimal
+// places, negative amounts and anything but plain decimal notation are rejected.
+func EtherToWei(ether string) (*big.Int, error) {
+	return parseUnits(ether, nativeDecimals, "Ether")
+}
+
+// GweiToWei converts a decimal Gwei amount such as "1.5" to Wei, with the same
+// rules as EtherToWei but at most 9 decimal places.
+func GweiToWei(gwei string) (*big.Int, error) {
+	return parseUnits(gwei, 9, "Gwei")
+}
+
+// parseUnits converts a plain decimal amount of a unit worth 10^decimals Wei to Wei.
+func parseUnits(amount string, decimals int, unit string) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	intPart, fracPart, _ := strings.Cut(amount, ".")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return nil, fmt.Errorf("invalid %s amount %q", unit, amount)
+	}
+	if len(fracPart) > decimals {
+		return nil, fmt.Errorf("invalid %s amount %q: more than %d decimal places", unit, amount, decimals)
+	}
+
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s amount %q", unit, amount)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	wei := value.Mul(value, new(big.Rat).SetInt(scale))
+	// At most `decimals` decimal places always yields a whole number of Wei.
+	return new(big.Int).Set(wei.Num()), nil
+}
+
+// isDigits reports whether s holds only ASCII digits (an empty s qualifies).
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 // GetBalanceEther is a convenience wrapper: returns the balance as a decimal string in Ether.
@@ -142,14 +393,48 @@ func (wallet *WalletInfo) GetBalanceEther(rpcURL string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	ethValue := WeiToEther(balanceWei)
-	// Format with necessary precision.
-	// Note: .Text('f', 18) prints exactly 18 decimal places.
-	// You can trim trailing zeros if desired.
-	str := ethValue.Text('f', 18)
-	// Optionally trim trailing zeros and dot:
-	str = trimTrailingZeros(str)
-	return str, nil
+	return FormatEther(balanceWei), nil
+}
+
+// FormatEther formats a Wei amount as a decimal Ether string without trailing zeros.
+func FormatEther(balanceWei *big.Int) string {
+	return FormatUnits(balanceWei, nativeDecimals)
+}
+
+// FormatUnits formats a Wei amount in a unit worth 10^decimals Wei, without
+// trailing zeros.
+func FormatUnits(amountWei *big.Int, decimals int) string {
+	// .Text('f', decimals) prints exactly that many decimal places.
+	return trimTrailingZeros(WeiToUnits(amountWei, decimals).Text('f', decimals))
+}
+
+// Units a balance can be formatted in, see FormatBalance.
+const (
+	UnitWei   = "wei"
+	UnitGwei  = "gwei"
+	UnitEther = "ether"
+)
+
+// ValidateBalanceUnit checks that unit is one FormatBalance understands.
+func ValidateBalanceUnit(unit string) error {
+	switch unit {
+	case UnitWei, UnitGwei, UnitEther:
+		return nil
+	}
+	return fmt.Errorf("invalid balance unit %q: want wei, gwei or ether", unit)
+}
+
+// FormatBalance formats a Wei amount as a decimal string in unit (wei, gwei or
+// ether), trimming trailing zeros like FormatEther. Unknown units fall back to ether.
+func FormatBalance(wei *big.Int, unit string) string {
+	switch unit {
+	case UnitWei:
+		return wei.String()
+	case UnitGwei:
+		return trimTrailingZeros(WeiToGwei(wei).Text('f', 9))
+	default:
+		return FormatEther(wei)
+	}
 }
 
 // trimTrailingZeros removes trailing zeros and possibly the decimal point if integer.
@@ -180,9 +465,19 @@ func trimTrailingZeros(s string) string {
 
 // SendEIP1559ETHTransfer sends an EIP-1559 transaction from the wallet at nonceIncrease,
 // with tipCap, maxFeeCap, and gasLimit. The recipient is the same as the wallet's address.
-// The transaction has a value of 100000 Wei.
+// The transaction carries value Wei.
 // Returns the signed transaction, or an error.
-func (wallet *WalletInfo) SendEIP1559ETHTransfer(chainId *big.Int, nonceIncrease uint64, tipCap *big.Int, maxFeeCap *big.Int, gasLimit uint64, value int64) (*types.Transaction, error) {
+func (wallet *WalletInfo) SendEIP1559ETHTransfer(chainId *big.Int, nonceIncrease uint64, tipCap *big.Int, maxFeeCap *big.Int, gasLimit uint64, value *big.Int) (*types.Transaction, error) {
+	return wallet.SendEIP1559Transaction(chainId, nonceIncrease, tipCap, maxFeeCap, gasLimit, &wallet.Address, value, nil)
+}
+
+// SendEIP1559Transaction signs an EIP-1559 transaction from the wallet at nonceIncrease
+// to `to`, carrying value Wei and calldata data.
+// Returns the signed transaction, or ErrNilChainID when chainId is unknown.
+func (wallet *WalletInfo) SendEIP1559Transaction(chainId *big.Int, nonceIncrease uint64, tipCap *big.Int, maxFeeCap *big.Int, gasLimit uint64, to *common.Address, value *big.Int, data []byte) (*types.Transaction, error) {
+	if chainId == nil {
+		return nil, ErrNilChainID
+	}
 
 	txData := &types.DynamicFeeTx{
 		ChainID:   chainId,
@@ -190,9 +485,9 @@ func (wallet *WalletInfo) SendEIP1559ETHTransfer(chainId *big.Int, nonceIncrease
 		GasTipCap: tipCap,
 		GasFeeCap: maxFeeCap,
 		Gas:       gasLimit,
-		To:        &wallet.Address,
-		Value:     big.NewInt((value)),
-		Data:      nil,
+		To:        to,
+		Value:     value,
+		Data:      data,
 		// AccessList: nil,
 	}
 	tx := types.NewTx(txData)
@@ -205,71 +500,494 @@ func (wallet *WalletInfo) SendEIP1559ETHTransfer(chainId *big.Int, nonceIncrease
 	return signedTx, nil
 }
 
-func (wallet *WalletInfo) SendEIP1559ETHTransferInBatch(chainId *big.Int, batch int) ([]*types.Transaction, error) {
-	client := wallet.Client
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
+// BumpEIP1559Fees re-signs tx at the same nonce with its tip and fee caps raised by
+// percent (rounded up, by at least 1 Wei), so it can replace tx in the mempool.
+// Most nodes only accept a replacement that pays at least 10% more.
+func (wallet *WalletInfo) BumpEIP1559Fees(tx *types.Transaction, percent uint64) (*types.Transaction, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		return nil, fmt.Errorf("cannot bump fees of transaction type %d", tx.Type())
+	}
 
-	nonce, err := client.PendingNonceAt(ctx, wallet.Address)
+	txData := &types.DynamicFeeTx{
+		ChainID:    tx.ChainId(),
+		Nonce:      tx.Nonce(),
+		GasTipCap:  bumpByPercent(tx.GasTipCap(), percent),
+		GasFeeCap:  BumpedFeeCap(tx, percent),
+		Gas:        tx.Gas(),
+		To:         tx.To(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+
+	signedTx, err := types.SignTx(types.NewTx(txData), types.NewLondonSigner(tx.ChainId()), wallet.PrivateKey)
 	if err != nil {
-		logger.Errorf("failed to get nonce: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to sign replacement tx: %w", err)
+	}
+
+	return signedTx, nil
+}
+
+// BumpedFeeCap returns the fee cap BumpEIP1559Fees gives the replacement of tx.
+func BumpedFeeCap(tx *types.Transaction, percent uint64) *big.Int {
+	return bumpByPercent(tx.GasFeeCap(), percent)
+}
+
+// ResignWithNonce re-signs tx unchanged but for its nonce, for a transaction
+// the node rejected because its nonce was already used.
+func (wallet *WalletInfo) ResignWithNonce(tx *types.Transaction, nonce uint64) (*types.Transaction, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		return nil, fmt.Errorf("cannot re-sign transaction type %d", tx.Type())
+	}
+
+	txData := &types.DynamicFeeTx{
+		ChainID:    tx.ChainId(),
+		Nonce:      nonce,
+		GasTipCap:  tx.GasTipCap(),
+		GasFeeCap:  tx.GasFeeCap(),
+		Gas:        tx.Gas(),
+		To:         tx.To(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+
+	signedTx, err := types.SignTx(types.NewTx(txData), types.NewLondonSigner(tx.ChainId()), wallet.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-sign tx: %w", err)
+	}
+
+	return signedTx, nil
+}
+
+// bumpByPercent returns v * (100 + percent) / 100 rounded up, and at least v + 1.
+func bumpByPercent(v *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(v, new(big.Int).SetUint64(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+
+	if bumped.Cmp(v) <= 0 {
+		bumped.Add(v, big.NewInt(1))
+	}
+	return bumped
+}
+
+// BatchOptions controls how SendEIP1559ETHTransferInBatch prices the transactions it builds.
+type BatchOptions struct {
+	// TipPercentile, when > 0, derives the tip from this percentile (0-100) of the
+	// rewards paid in recent blocks instead of the node's SuggestGasTipCap.
+	TipPercentile float64
+	// TipBlocks is how many recent blocks are sampled for TipPercentile.
+	TipBlocks uint64
+	// Fees, when set, is used as-is instead of estimating gas and fetching
+	// fee data for every batch.
+	Fees *FeeData
+	// Nonce, when set, is the nonce of the first transaction of the batch
+	// instead of the wallet's pending nonce fetched from the node.
+	Nonce *uint64
+	// Value is the amount of Wei each transaction carries; nil means TransferValue.
+	Value *big.Int
+	// Values, when set, draws the value of each transaction instead of Value,
+	// clamped so the batch stays within the wallet's balance.
+	Values *ValueSampler
+	// To is the recipient of every transaction; nil sends to the wallet itself.
+	To *common.Address
+	// Data is the calldata of every transaction.
+	Data []byte
+	// Tag, when set, returns bytes appended to Data for the transaction at
+	// each nonce, so transactions can be told apart on-chain. Gas is
+	// estimated as if every tag byte were non-zero, the costlier case.
+	Tag func(nonce uint64) []byte
+	// Sequence, when set, describes the transactions of the batch one by one,
+	// in nonce order; it must hold at least as many entries as the batch.
+	Sequence []TxSpec
+	// MaxFee, when set, is the fee cap of every transaction; the tip is then
+	// filled in as min(suggested tip, MaxFee - baseFee).
+	MaxFee *big.Int
+	// MinTip, when set, is the lowest tip the batch is priced with: a lower
+	// suggested tip is raised to it, along with the fee cap.
+	MinTip *big.Int
+	// Oracle, when set, supplies the tip, fee cap and base fee instead of the
+	// node; if it fails the fees are estimated from the node as usual.
+	Oracle *rpc.OracleClient
+	// Override, when set, replaces the tip and/or fee cap of this batch's fees.
+	Override *FeeOverride
+	// Log receives the records about this batch; nil uses the package logger.
+	Log *logger.Logger
+	// OnFees, when set, is called with the fees the batch is actually built with.
+	OnFees func(fees *FeeData)
+	// Signer selects the transaction type and signer; "" means SignerLondon.
+	// Legacy signers price each transaction with MaxFeeCap as its gas price.
+	Signer Signer
+	// GasMultiplier, when > 0, scales every gas estimate, rounded up,
+	// instead of adding GasBuffer to it.
+	GasMultiplier float64
+}
+
+// FeeOverride replaces parts of the fees a wallet sends with; nil fields keep the default.
+type FeeOverride struct {
+	TipCap    *big.Int
+	MaxFeeCap *big.Int
+}
+
+// Apply returns a copy of fees with the override applied. When only the tip
+// is overridden, the fee cap is re-derived as 2 * baseFee + tip so it still covers it.
+func (o *FeeOverride) Apply(fees *FeeData) *FeeData {
+	out := *fees
+	if o.TipCap != nil {
+		out.TipCap = o.TipCap
+		if o.MaxFeeCap == nil && out.BaseFee != nil {
+			out.MaxFeeCap = new(big.Int).Add(new(big.Int).Mul(out.BaseFee, big.NewInt(2)), o.TipCap)
+		}
+	}
+	if o.MaxFeeCap != nil {
+		out.MaxFeeCap = o.MaxFeeCap
+		if out.TipCap.Cmp(out.MaxFeeCap) > 0 {
+			out.TipCap = out.MaxFeeCap
+		}
+	}
+	return &out
+}
+
+// recipient returns where transactions from wallet built with opts are sent.
+func (opts BatchOptions) recipient(wallet *WalletInfo) *common.Address {
+	if opts.To == nil {
+		return &wallet.Address
+	}
+	return opts.To
+}
+
+// txData returns the calldata of the transaction built with opts at nonce.
+func (opts BatchOptions) txData(nonce uint64) []byte {
+	if opts.Tag == nil {
+		return opts.Data
+	}
+	return append(slices.Clip(opts.Data), opts.Tag(nonce)...)
+}
+
+// estimateData returns the calldata gas is estimated with: Data followed by
+// as many non-zero bytes as a tag holds.
+func (opts BatchOptions) estimateData() []byte {
+	if opts.Tag == nil {
+		return opts.Data
+	}
+	return append(slices.Clip(opts.Data), bytes.Repeat([]byte{0xff}, len(opts.Tag(0)))...)
+}
+
+// gasLimit returns the gas limit of a transaction estimated at estimate:
+// estimate * GasMultiplier rounded up, or estimate + GasBuffer. The
+// multiplier is taken as the decimal it prints as, so 1.1 scales 21000 to
+// 23100 rather than one more.
+func (opts BatchOptions) gasLimit(estimate uint64) uint64 {
+	if opts.GasMultiplier <= 0 {
+		return estimate + GasBuffer
+	}
+	scaled, ok := new(big.Rat).SetString(strconv.FormatFloat(opts.GasMultiplier, 'g', -1, 64))
+	if !ok {
+		return estimate + GasBuffer
+	}
+	scaled.Mul(scaled, new(big.Rat).SetUint64(estimate))
+	limit := new(big.Int).Add(scaled.Num(), scaled.Denom())
+	limit.Sub(limit, big.NewInt(1))
+	limit.Quo(limit, scaled.Denom())
+	if !limit.IsUint64() {
+		return math.MaxUint64
+	}
+	return limit.Uint64()
+}
+
+// TxValue returns the Wei each transaction built with opts carries.
+func (opts BatchOptions) TxValue() *big.Int {
+	if opts.Value == nil {
+		return big.NewInt(TransferValue)
+	}
+	return opts.Value
+}
+
+// suggestTipCap returns the priority fee to use for the batch, following opts.
+func (wallet *WalletInfo) suggestTipCap(ctx context.Context, opts BatchOptions) (*big.Int, error) {
+	if opts.TipPercentile > 0 {
+		tipCap, err := rpc.SuggestTipFromFeeHistory(ctx, wallet.Client, opts.TipBlocks, opts.TipPercentile)
+		if err != nil {
+			return nil, err
+		}
+		opts.Log.Debugf("tip from %v percentile of last %d blocks: %s wei", opts.TipPercentile, opts.TipBlocks, tipCap)
+		return tipCap, nil
 	}
 
+	return wallet.Client.SuggestGasTipCap(ctx)
+}
+
+// FeeData holds the gas limit and EIP-1559 fee caps used to price a batch.
+type FeeData struct {
+	GasLimit  uint64
+	BaseFee   *big.Int
+	TipCap    *big.Int
+	MaxFeeCap *big.Int
+}
+
+// TxCost returns the most a single transaction priced with fees can cost:
+// value + gasLimit * maxFeeCap.
+func (fees *FeeData) TxCost(value *big.Int) *big.Int {
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(fees.GasLimit), fees.MaxFeeCap)
+	return cost.Add(cost, value)
+}
+
+// FetchFeeData estimates the gas of a transaction from the wallet built with opts
+// (a self-transfer by default) and prices it from opts.Oracle when set, or else
+// from the latest block: maxFeeCap = 2 * baseFee + tipCap. The tip is then
+// raised to opts.MinTip if it falls below it.
+func (wallet *WalletInfo) FetchFeeData(ctx context.Context, opts BatchOptions) (*FeeData, error) {
+	fees, err := wallet.fetchFeeData(ctx, opts)
+	if err != nil || opts.MinTip == nil {
+		return fees, err
+	}
+	return applyMinTip(opts.Log, fees, opts.MinTip, opts.MaxFee), nil
+}
+
+func (wallet *WalletInfo) fetchFeeData(ctx context.Context, opts BatchOptions) (*FeeData, error) {
+	client := wallet.Client
+
 	msg := ethereum.CallMsg{
 		From:  wallet.Address,
-		To:    &wallet.Address,
-		Value: big.NewInt(100000000000),
-		Data:  nil,
+		To:    opts.recipient(wallet),
+		Value: opts.TxValue(),
+		Data:  opts.estimateData(),
 	}
 
-	gasLimit, err := wallet.Client.EstimateGas(ctx, msg)
+	estimate, err := client.EstimateGas(ctx, msg)
 	if err != nil {
-		logger.Errorf("failed to estimate gas: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	gasLimit := opts.gasLimit(estimate)
+
+	if opts.Oracle != nil {
+		fees, err := opts.Oracle.Fees(ctx)
+		if err == nil {
+			opts.Log.Debugf("fees from gas oracle: tip %s wei, max fee %s wei, base fee %s wei", fees.Tip, fees.MaxFee, fees.BaseFee)
+			if opts.MaxFee != nil {
+				return capFees(opts.Log, gasLimit, fees.BaseFee, fees.Tip, opts.MaxFee)
+			}
+			return &FeeData{
+				GasLimit:  gasLimit,
+				BaseFee:   fees.BaseFee,
+				TipCap:    fees.Tip,
+				MaxFeeCap: fees.MaxFee,
+			}, nil
+		}
+		opts.Log.Warnf("%v; falling back to node fee estimation", err)
 	}
 
-	// Optionally add buffer:
-	gasLimit += 1000
+	if opts.Signer.Legacy() {
+		return wallet.legacyFees(ctx, opts, gasLimit)
+	}
 
-	tipCap, err := client.SuggestGasTipCap(ctx)
+	tipCap, err := wallet.suggestTipCap(ctx, opts)
 	if err != nil {
-		logger.Errorf("failed to suggest tip cap: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to suggest tip cap: %w", err)
 	}
 
 	header, err := client.HeaderByNumber(ctx, nil)
 	if err != nil {
-		logger.Errorf("failed to fetch latest header: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to fetch latest header: %w", err)
+	}
+
+	// Some nodes answer with a null header and no error, e.g. while syncing.
+	if header == nil {
+		return nil, errors.New("node returned no latest header")
 	}
 
 	if header.BaseFee == nil {
-		logger.Errorf("node does not return base fee (non-EIP-1559?)")
-		return nil,	err
+		return nil, errors.New("node does not return base fee (non-EIP-1559?)")
 	}
 
 	baseFee := header.BaseFee
 
+	if opts.MaxFee != nil {
+		return capFees(opts.Log, gasLimit, baseFee, tipCap, opts.MaxFee)
+	}
+
 	maxFeeCap := new(big.Int).Add(
 		new(big.Int).Mul(baseFee, big.NewInt(2)),
 		tipCap,
 	)
 
-	var txs []*types.Transaction
+	return &FeeData{
+		GasLimit:  gasLimit,
+		BaseFee:   baseFee,
+		TipCap:    tipCap,
+		MaxFeeCap: maxFeeCap,
+	}, nil
+}
 
-	for i := (0); i < batch; i++ {
-		tx, err := wallet.SendEIP1559ETHTransfer(chainId, nonce+uint64(i), tipCap, maxFeeCap, gasLimit, 10000)
+// legacyFees prices a legacy transaction at the node's suggested gas price,
+// capped at opts.MaxFee. The price fills both fee caps, as a legacy
+// transaction pays all of it whatever the base fee.
+func (wallet *WalletInfo) legacyFees(ctx context.Context, opts BatchOptions, gasLimit uint64) (*FeeData, error) {
+	gasPrice, err := wallet.Client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas price: %w", err)
+	}
+	if opts.MaxFee != nil && gasPrice.Cmp(opts.MaxFee) > 0 {
+		opts.Log.Infof("suggested gas price %s wei capped at max fee %s wei", gasPrice, opts.MaxFee)
+		gasPrice = new(big.Int).Set(opts.MaxFee)
+	}
+
+	return &FeeData{
+		GasLimit:  gasLimit,
+		BaseFee:   new(big.Int),
+		TipCap:    gasPrice,
+		MaxFeeCap: gasPrice,
+	}, nil
+}
+
+// applyMinTip raises the tip of fees to minTip, on quiet chains nodes can
+// suggest a tip of (nearly) zero that leaves transactions stuck. The fee cap
+// grows by the same amount, unless it is the user's maxFee: the tip is then
+// kept within maxFee - baseFee.
+func applyMinTip(log *logger.Logger, fees *FeeData, minTip, maxFee *big.Int) *FeeData {
+	if fees.TipCap.Cmp(minTip) >= 0 {
+		return fees
+	}
+
+	out := *fees
+	out.TipCap = new(big.Int).Set(minTip)
+	if maxFee == nil {
+		raise := new(big.Int).Sub(minTip, fees.TipCap)
+		out.MaxFeeCap = new(big.Int).Add(fees.MaxFeeCap, raise)
+	} else if room := new(big.Int).Sub(fees.MaxFeeCap, fees.BaseFee); out.TipCap.Cmp(room) > 0 {
+		out.TipCap = room
+		log.Warnf("min tip %s wei exceeds what max fee %s wei leaves above the base fee, tip capped at %s wei", minTip, fees.MaxFeeCap, room)
+	}
+	log.Infof("suggested tip %s wei is below the floor, raised to %s wei (max fee %s wei)", fees.TipCap, out.TipCap, out.MaxFeeCap)
+	return &out
+}
+
+// capFees prices a transaction under a user-supplied fee ceiling: the tip is the
+// suggested one, lowered so baseFee + tip never exceeds maxFee.
+// It fails when maxFee does not even cover baseFee, as the transaction could never be mined.
+func capFees(log *logger.Logger, gasLimit uint64, baseFee, suggestedTip, maxFee *big.Int) (*FeeData, error) {
+	if maxFee.Cmp(baseFee) <= 0 {
+		return nil, fmt.Errorf("max fee %s wei does not exceed the base fee %s wei", maxFee, baseFee)
+	}
+
+	tipCap := new(big.Int).Sub(maxFee, baseFee)
+	if suggestedTip.Cmp(tipCap) < 0 {
+		tipCap.Set(suggestedTip)
+	}
+	log.Infof("tip derived from max fee %s wei: %s wei (suggested %s, base fee %s)", maxFee, tipCap, suggestedTip, baseFee)
+
+	return &FeeData{
+		GasLimit:  gasLimit,
+		BaseFee:   baseFee,
+		TipCap:    tipCap,
+		MaxFeeCap: new(big.Int).Set(maxFee),
+	}, nil
+}
+
+// valueBudget returns the Wei the wallet can spread over the values of a batch
+// of size transactions priced with fees: its balance minus their worst-case gas.
+func (wallet *WalletInfo) valueBudget(ctx context.Context, size int, fees *FeeData) (*big.Int, error) {
+	balance, err := wallet.Client.BalanceAt(ctx, wallet.Address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	gas := new(big.Int).Mul(fees.TxCost(new(big.Int)), big.NewInt(int64(size)))
+	budget := balance.Sub(balance, gas)
+	if budget.Sign() < 0 {
+		budget.SetInt64(0)
+	}
+	return budget, nil
+}
+
+// BatchError is returned by SendEIP1559ETHTransferInBatch when it could not
+// build the whole batch: Built of the Requested transactions were signed, at
+// consecutive nonces, before Err stopped it.
+type BatchError struct {
+	Requested int
+	Built     int
+	Err       error
+}
+
+func (e *BatchError) Error() string {
+	if e.Built == 0 {
+		return fmt.Sprintf("failed to build %d transactions: %v", e.Requested, e.Err)
+	}
+	return fmt.Sprintf("built %d of %d transactions: %v", e.Built, e.Requested, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// SendEIP1559ETHTransferInBatch signs batch transactions from the wallet at
+// consecutive nonces, priced and shaped by opts, without broadcasting them.
+// It returns all of them, or else the ones signed before the first failure,
+// possibly none, with a *BatchError; a transaction that could not be signed
+// would leave a gap no later nonce could be mined past.
+func (wallet *WalletInfo) SendEIP1559ETHTransferInBatch(chainId *big.Int, batch int, opts BatchOptions) ([]*types.Transaction, error) {
+	client := wallet.Client
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	txs := make([]*types.Transaction, 0, batch)
+	fail := func(err error) ([]*types.Transaction, error) {
+		return txs, &BatchError{Requested: batch, Built: len(txs), Err: err}
+	}
+
+	var (
+		nonce uint64
+		err   error
+	)
+	if opts.Nonce != nil {
+		nonce = *opts.Nonce
+	} else if nonce, err = client.PendingNonceAt(ctx, wallet.Address); err != nil {
+		return fail(fmt.Errorf("failed to get nonce: %w", err))
+	}
+
+	fees := opts.Fees
+	if fees == nil {
+		fees, err = wallet.FetchFeeData(ctx, opts)
 		if err != nil {
-			logger.Errorf("failed to create transaction: %v", err)
+			return fail(err)
+		}
+	}
+	if opts.Override != nil {
+		fees = opts.Override.Apply(fees)
+		opts.Log.Infof("wallet %s fee override: tip %s wei, max fee %s wei", wallet.Name(), fees.TipCap, fees.MaxFeeCap)
+	}
+	if opts.OnFees != nil {
+		opts.OnFees(fees)
+	}
 
-		} else {
-			logger.Debugf("Transaction created successfully: %d/%d", i, batch)
-			txs = append(txs, tx)
+	var budget *big.Int
+	if opts.Values != nil {
+		if budget, err = wallet.valueBudget(ctx, batch, fees); err != nil {
+			return fail(err)
+		}
+	}
+
+	for i := (0); i < batch; i++ {
+		value := opts.TxValue()
+		if opts.Values != nil {
+			value = opts.Values.Next(budget)
+			budget.Sub(budget, value)
+		}
+		to, data, txFees := opts.recipient(wallet), opts.txData(nonce+uint64(i)), fees
+		if i < len(opts.Sequence) {
+			if to, value, data, txFees, err = wallet.applySpec(ctx, opts, opts.Sequence[i], to, value, data, fees); err != nil {
+				return fail(fmt.Errorf("transaction %d of the sequence: %w", i, err))
+			}
+		}
+		tx, err := wallet.SignTransaction(opts.Signer, chainId, nonce+uint64(i), txFees, to, value, data)
+		if err != nil {
+			return fail(fmt.Errorf("failed to create transaction at nonce %d: %w", nonce+uint64(i), err))
 		}
+		opts.Log.Debugf("Transaction created successfully: %d/%d", i, batch)
+		txs = append(txs, tx)
 	}
 
-	logger.Infof("Sending %d transactions...", len(txs))
+	opts.Log.Infof("Sending %d transactions...", len(txs))
 
 	return txs, nil
 }
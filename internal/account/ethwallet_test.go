@@ -0,0 +1,134 @@
+package ethwallet
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// testKey is the first account of the "test test ... junk" mnemonic.
+const testKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// stubBackend is a NullBackend whose answers can be replaced one call at a time.
+type stubBackend struct {
+	*rpc.NullBackend
+	header   func() (*types.Header, error)
+	nonce    func() (uint64, error)
+	estimate func() (uint64, error)
+}
+
+func (b *stubBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if b.nonce != nil {
+		return b.nonce()
+	}
+	return b.NullBackend.PendingNonceAt(ctx, account)
+}
+
+func (b *stubBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	if b.estimate != nil {
+		return b.estimate()
+	}
+	return b.NullBackend.EstimateGas(ctx, msg)
+}
+
+func (b *stubBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if b.header != nil {
+		return b.header()
+	}
+	return b.NullBackend.HeaderByNumber(ctx, number)
+}
+
+func testWallet(t *testing.T, client rpc.EthBackend) *WalletInfo {
+	t.Helper()
+	wallet, err := WalletFromHexKey(testKey, client, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wallet
+}
+
+func TestBatchNilHeader(t *testing.T) {
+	client := &stubBackend{
+		NullBackend: rpc.NewNullBackend(),
+		header:      func() (*types.Header, error) { return nil, nil },
+	}
+	wallet := testWallet(t, client)
+
+	txs, err := wallet.SendEIP1559ETHTransferInBatch(rpc.NullChainID, 3, BatchOptions{})
+	if err == nil {
+		t.Fatal("batch built without a latest header")
+	}
+	if !strings.Contains(err.Error(), "no latest header") {
+		t.Errorf("error = %q, want it to say the node returned no latest header", err)
+	}
+	if len(txs) != 0 {
+		t.Errorf("got %d transactions, want 0", len(txs))
+	}
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Errorf("error %T is not a *BatchError", err)
+	}
+}
+
+func TestBatchFailures(t *testing.T) {
+	errNode := errors.New("node unavailable")
+	for _, c := range []struct {
+		name    string
+		backend *stubBackend
+		want    string
+		cause   error
+	}{
+		{
+			name:    "nonce fetch",
+			backend: &stubBackend{nonce: func() (uint64, error) { return 0, errNode }},
+			want:    "failed to get nonce",
+			cause:   errNode,
+		},
+		{
+			name:    "gas estimate",
+			backend: &stubBackend{estimate: func() (uint64, error) { return 0, errNode }},
+			want:    "failed to estimate gas",
+			cause:   errNode,
+		},
+		{
+			name: "nil base fee",
+			backend: &stubBackend{header: func() (*types.Header, error) {
+				return &types.Header{Number: big.NewInt(1)}, nil
+			}},
+			want: "does not return base fee",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			c.backend.NullBackend = rpc.NewNullBackend()
+			wallet := testWallet(t, c.backend)
+
+			txs, err := wallet.SendEIP1559ETHTransferInBatch(rpc.NullChainID, 3, BatchOptions{})
+			if txs == nil && err == nil {
+				t.Fatal("batch returned neither transactions nor an error")
+			}
+			if len(txs) != 0 {
+				t.Errorf("got %d transactions, want 0", len(txs))
+			}
+			var batchErr *BatchError
+			if !errors.As(err, &batchErr) {
+				t.Fatalf("error %v (%T) is not a *BatchError", err, err)
+			}
+			if batchErr.Built != 0 || batchErr.Requested != 3 {
+				t.Errorf("BatchError built %d of %d, want 0 of 3", batchErr.Built, batchErr.Requested)
+			}
+			if !strings.Contains(err.Error(), c.want) {
+				t.Errorf("error = %q, want it to contain %q", err, c.want)
+			}
+			if c.cause != nil && !errors.Is(err, c.cause) {
+				t.Errorf("error %v does not wrap %v", err, c.cause)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package ethwallet
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DefaultRetryablePatterns match transient broadcast failures: timeouts,
+// dropped connections, rate limiting and a node that already holds the transaction.
+var DefaultRetryablePatterns = []string{
+	`timeout`,
+	`deadline exceeded`,
+	`connection reset`,
+	`connection refused`,
+	`broken pipe`,
+	`unexpected EOF`,
+	`too many requests`,
+	`already known`,
+}
+
+// RetryClassifier decides whether a broadcast error is worth retrying by
+// matching its message against a list of case-insensitive regular expressions.
+type RetryClassifier struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRetryClassifier compiles patterns; a plain substring is a valid pattern.
+func NewRetryClassifier(patterns []string) (*RetryClassifier, error) {
+	c := &RetryClassifier{}
+	for _, p := range patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retryable error pattern %q: %w", p, err)
+		}
+		c.patterns = append(c.patterns, re)
+	}
+	return c, nil
+}
+
+// Retryable reports whether err matches one of the patterns. nil never does.
+func (c *RetryClassifier) Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, re := range c.patterns {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadRetryablePatterns reads one pattern per line from path, skipping blank
+// lines and lines starting with '#'.
+func LoadRetryablePatterns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retryable errors: %w", err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read retryable errors: %w", err)
+	}
+	return patterns, nil
+}
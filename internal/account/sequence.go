@@ -0,0 +1,82 @@
+package ethwallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TxSpec describes one transaction of a BatchOptions.Sequence. Nil and zero
+// fields keep what the batch would otherwise use.
+type TxSpec struct {
+	To    *common.Address
+	Value *big.Int
+	Data  []byte
+	// GasLimit, when 0, is estimated for the transaction whenever it sets To
+	// or Data, and taken from the batch's fees otherwise.
+	GasLimit  uint64
+	TipCap    *big.Int
+	MaxFeeCap *big.Int
+}
+
+// custom reports whether spec changes the call itself, which then needs its
+// own gas estimate.
+func (spec TxSpec) custom() bool {
+	return spec.To != nil || spec.Data != nil
+}
+
+// applySpec returns the recipient, value, calldata and fees of the
+// transaction spec describes, from those of the batch built with opts.
+func (wallet *WalletInfo) applySpec(ctx context.Context, opts BatchOptions, spec TxSpec, to *common.Address, value *big.Int, data []byte, fees *FeeData) (*common.Address, *big.Int, []byte, *FeeData, error) {
+	if spec.To != nil {
+		to = spec.To
+	}
+	if spec.Value != nil {
+		value = spec.Value
+	}
+	if spec.Data != nil {
+		data = spec.Data
+	}
+
+	specFees := *fees
+	switch {
+	case spec.GasLimit > 0:
+		specFees.GasLimit = spec.GasLimit
+	case spec.custom():
+		estimate, err := wallet.Client.EstimateGas(ctx, ethereum.CallMsg{From: wallet.Address, To: to, Value: value, Data: data})
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		specFees.GasLimit = opts.gasLimit(estimate)
+	}
+	if spec.TipCap != nil {
+		specFees.TipCap = spec.TipCap
+	}
+	if spec.MaxFeeCap != nil {
+		specFees.MaxFeeCap = spec.MaxFeeCap
+	}
+	if specFees.TipCap.Cmp(specFees.MaxFeeCap) > 0 {
+		specFees.TipCap = specFees.MaxFeeCap
+	}
+	return to, value, data, &specFees, nil
+}
+
+// SequenceCost returns the most the transactions of opts.Sequence can cost when
+// wallet sends them in a batch priced with fees: their gas at each one's limit
+// and max fee, and the value they transfer. Specs that change the call are
+// estimated as the batch would.
+func (wallet *WalletInfo) SequenceCost(ctx context.Context, opts BatchOptions, fees *FeeData) (gas, value *big.Int, err error) {
+	gas, value = new(big.Int), new(big.Int)
+	for i, spec := range opts.Sequence {
+		_, txValue, _, txFees, err := wallet.applySpec(ctx, opts, spec, opts.recipient(wallet), opts.TxValue(), opts.estimateData(), fees)
+		if err != nil {
+			return nil, nil, fmt.Errorf("transaction %d of the sequence: %w", i, err)
+		}
+		gas.Add(gas, txFees.TxCost(new(big.Int)))
+		value.Add(value, txValue)
+	}
+	return gas, value, nil
+}
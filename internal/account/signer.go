@@ -0,0 +1,81 @@
+package ethwallet
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Signer selects how transactions are built and signed (see ParseSigner).
+type Signer string
+
+// Signers accepted by ParseSigner.
+const (
+	// SignerLondon signs EIP-1559 transactions, the default.
+	SignerLondon Signer = "london"
+	// SignerEIP155 signs legacy transactions with EIP-155 replay protection.
+	SignerEIP155 Signer = "eip155"
+	// SignerHomestead signs legacy transactions without a chain ID, for old
+	// chains that predate EIP-155.
+	SignerHomestead Signer = "homestead"
+)
+
+// ParseSigner parses "london", "eip155" or "homestead"; "" means london.
+func ParseSigner(s string) (Signer, error) {
+	switch signer := Signer(s); signer {
+	case "":
+		return SignerLondon, nil
+	case SignerLondon, SignerEIP155, SignerHomestead:
+		return signer, nil
+	default:
+		return "", fmt.Errorf("unknown signer %q (want %s, %s or %s)", s, SignerLondon, SignerEIP155, SignerHomestead)
+	}
+}
+
+// Legacy reports whether s signs legacy (pre-EIP-1559) transactions, priced
+// with a single gas price.
+func (s Signer) Legacy() bool {
+	return s == SignerEIP155 || s == SignerHomestead
+}
+
+// SignTransaction signs a transaction of the type signer selects from the wallet
+// at nonce to `to`: a legacy one priced at fees.MaxFeeCap, or an EIP-1559 one
+// with the tip and fee caps of fees.
+func (wallet *WalletInfo) SignTransaction(signer Signer, chainId *big.Int, nonce uint64, fees *FeeData, to *common.Address, value *big.Int, data []byte) (*types.Transaction, error) {
+	if signer.Legacy() {
+		return wallet.SendLegacyTransaction(signer, chainId, nonce, fees.MaxFeeCap, fees.GasLimit, to, value, data)
+	}
+	return wallet.SendEIP1559Transaction(chainId, nonce, fees.TipCap, fees.MaxFeeCap, fees.GasLimit, to, value, data)
+}
+
+// SendLegacyTransaction signs a legacy transaction from the wallet at nonce to
+// `to`, carrying value Wei and calldata data at gasPrice, with the EIP-155
+// signer for chainId or, when signer is SignerHomestead, without replay protection.
+// Returns the signed transaction, or ErrNilChainID when EIP-155 needs an unknown chainId.
+func (wallet *WalletInfo) SendLegacyTransaction(signer Signer, chainId *big.Int, nonce uint64, gasPrice *big.Int, gasLimit uint64, to *common.Address, value *big.Int, data []byte) (*types.Transaction, error) {
+	var txSigner types.Signer = types.HomesteadSigner{}
+	if signer != SignerHomestead {
+		if chainId == nil {
+			return nil, ErrNilChainID
+		}
+		txSigner = types.NewEIP155Signer(chainId)
+	}
+
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      gasLimit,
+		To:       to,
+		Value:    value,
+		Data:     data,
+	})
+
+	signedTx, err := types.SignTx(tx, txSigner, wallet.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign tx: %w", err)
+	}
+
+	return signedTx, nil
+}
@@ -0,0 +1,36 @@
+package ethwallet
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+func TestSignNilChainID(t *testing.T) {
+	wallet := testWallet(t, rpc.NewNullBackend())
+	fees := &FeeData{GasLimit: 21000, BaseFee: big.NewInt(1), TipCap: big.NewInt(1), MaxFeeCap: big.NewInt(3)}
+
+	for _, signer := range []Signer{SignerLondon, SignerEIP155} {
+		t.Run(string(signer), func(t *testing.T) {
+			tx, err := wallet.SignTransaction(signer, nil, 0, fees, &wallet.Address, big.NewInt(1), nil)
+			if !errors.Is(err, ErrNilChainID) {
+				t.Errorf("error = %v, want %v", err, ErrNilChainID)
+			}
+			if tx != nil {
+				t.Error("signed a transaction without a chain ID")
+			}
+		})
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		txs, err := wallet.SendEIP1559ETHTransferInBatch(nil, 2, BatchOptions{Fees: fees})
+		if !errors.Is(err, ErrNilChainID) {
+			t.Errorf("error = %v, want %v", err, ErrNilChainID)
+		}
+		if len(txs) != 0 {
+			t.Errorf("got %d transactions, want 0", len(txs))
+		}
+	})
+}
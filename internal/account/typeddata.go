@@ -0,0 +1,72 @@
+package ethwallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// VerifierMethod is the verifier contract function typed-data signatures are sent to.
+const VerifierMethod = "verify(bytes32,bytes)"
+
+// LoadTypedData reads an EIP-712 payload (types, primaryType, domain, message) from a JSON file.
+func LoadTypedData(path string) (apitypes.TypedData, error) {
+	var data apitypes.TypedData
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return data, fmt.Errorf("failed to read typed data: %w", err)
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return data, fmt.Errorf("failed to parse typed data %s: %w", path, err)
+	}
+
+	// Hash once so a malformed payload is reported up front.
+	if _, _, err := apitypes.TypedDataAndHash(data); err != nil {
+		return data, fmt.Errorf("invalid typed data %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// SignTypedData signs the EIP-712 digest of data with the wallet's key.
+// Returns the 65-byte [R || S || V] signature with V as 27/28, as eth_signTypedData does.
+func (wallet *WalletInfo) SignTypedData(data apitypes.TypedData) ([]byte, error) {
+	digest, _, err := apitypes.TypedDataAndHash(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+
+	sig, err := crypto.Sign(digest, wallet.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign typed data: %w", err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+
+	return sig, nil
+}
+
+// VerifierCalldata ABI-encodes a call to VerifierMethod with the EIP-712 digest of data and sig.
+func VerifierCalldata(data apitypes.TypedData, sig []byte) ([]byte, error) {
+	digest, _, err := apitypes.TypedDataAndHash(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+
+	bytes32, _ := abi.NewType("bytes32", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	args := abi.Arguments{{Type: bytes32}, {Type: bytesType}}
+
+	var digestArg [32]byte
+	copy(digestArg[:], digest)
+	packed, err := args.Pack(digestArg, sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode verifier call: %w", err)
+	}
+
+	selector := crypto.Keccak256([]byte(VerifierMethod))[:4]
+	return append(selector, packed...), nil
+}
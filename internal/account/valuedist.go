@@ -0,0 +1,121 @@
+package ethwallet
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"strings"
+)
+
+// Value distribution kinds accepted by ParseValueDistribution.
+const (
+	DistUniform     = "uniform"
+	DistNormal      = "normal"
+	DistExponential = "exponential"
+)
+
+// ValueDistribution describes how transaction values are drawn, in Wei:
+// uniform between A and B, normal with mean A and standard deviation B, or
+// exponential with mean A.
+type ValueDistribution struct {
+	Kind string
+	A, B float64
+}
+
+// ParseValueDistribution parses "uniform:min,max", "normal:mean,stddev" or
+// "exponential:mean", with every parameter given in Ether.
+func ParseValueDistribution(spec string) (*ValueDistribution, error) {
+	kind, params, _ := strings.Cut(spec, ":")
+	var want int
+	switch kind {
+	case DistUniform, DistNormal:
+		want = 2
+	case DistExponential:
+		want = 1
+	default:
+		return nil, fmt.Errorf("unknown value distribution %q (want uniform, normal or exponential)", kind)
+	}
+
+	fields := strings.Split(params, ",")
+	if params == "" || len(fields) != want {
+		return nil, fmt.Errorf("value distribution %s takes %d parameter(s) in Ether, got %q", kind, want, params)
+	}
+	values := make([]float64, want)
+	for i, field := range fields {
+		wei, err := EtherToWei(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("value distribution %s: %w", kind, err)
+		}
+		values[i], _ = new(big.Float).SetInt(wei).Float64()
+	}
+
+	d := &ValueDistribution{Kind: kind, A: values[0]}
+	if want == 2 {
+		d.B = values[1]
+	}
+	if kind == DistUniform && d.B < d.A {
+		return nil, fmt.Errorf("value distribution uniform: max %s is below min %s", fields[1], fields[0])
+	}
+	if kind == DistExponential && d.A == 0 {
+		return nil, fmt.Errorf("value distribution exponential: mean must be positive")
+	}
+	return d, nil
+}
+
+// Mean returns the expected value of the distribution in Wei, before clamping.
+func (d *ValueDistribution) Mean() *big.Int {
+	mean := d.A
+	if d.Kind == DistUniform {
+		mean = (d.A + d.B) / 2
+	}
+	return floatWei(mean)
+}
+
+// String formats d the way ParseValueDistribution reads it.
+func (d *ValueDistribution) String() string {
+	a := FormatEther(floatWei(d.A))
+	if d.Kind == DistExponential {
+		return d.Kind + ":" + a
+	}
+	return d.Kind + ":" + a + "," + FormatEther(floatWei(d.B))
+}
+
+// NewSampler returns a sampler drawing from d with an RNG seeded by seed and
+// stream. Giving every wallet its own stream keeps runs with the same seed
+// reproducible however the wallets' goroutines are scheduled.
+func (d *ValueDistribution) NewSampler(seed, stream uint64) *ValueSampler {
+	return &ValueSampler{dist: *d, rng: rand.New(rand.NewPCG(seed, stream))}
+}
+
+// ValueSampler draws transaction values from a ValueDistribution. It is not
+// safe for concurrent use.
+type ValueSampler struct {
+	dist ValueDistribution
+	rng  *rand.Rand
+}
+
+// Next draws a value in Wei, clamped to [0, limit]; a nil limit only clamps at 0.
+func (s *ValueSampler) Next(limit *big.Int) *big.Int {
+	var v float64
+	switch s.dist.Kind {
+	case DistUniform:
+		v = s.dist.A + s.rng.Float64()*(s.dist.B-s.dist.A)
+	case DistNormal:
+		v = s.dist.A + s.rng.NormFloat64()*s.dist.B
+	case DistExponential:
+		v = s.rng.ExpFloat64() * s.dist.A
+	}
+
+	value := floatWei(math.Max(v, 0))
+	if limit != nil && value.Cmp(limit) > 0 {
+		value.Set(limit)
+	}
+	return value
+}
+
+// floatWei truncates a non-negative float amount of Wei to an integer.
+func floatWei(v float64) *big.Int {
+	wei, _ := big.NewFloat(v).Int(nil)
+	return wei
+}
@@ -0,0 +1,83 @@
+package logger
+
+import "fmt"
+
+// Logger is a child logger: it writes through the package outputs and settings
+// but adds its own fields to every record, e.g. the wallet a goroutine works for.
+// It is immutable, so one Logger can be shared by any number of goroutines.
+// A nil *Logger logs exactly like the package-level functions.
+type Logger struct {
+	fields []Field
+}
+
+// With returns a child logger adding the alternating keys and values to every record.
+func With(keysAndValues ...interface{}) *Logger {
+	return (*Logger)(nil).With(keysAndValues...)
+}
+
+// With returns a child of l with more fields; l itself is unchanged.
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	var fields []Field
+	if l != nil {
+		fields = append(fields, l.fields...)
+	}
+	return &Logger{fields: append(fields, kvFields(keysAndValues)...)}
+}
+
+// emit logs msg with the logger's fields followed by extra.
+func (l *Logger) emit(level Level, msg string, extra []Field) {
+	if l == nil || len(l.fields) == 0 {
+		emit(level, msg, extra)
+		return
+	}
+	fields := make([]Field, 0, len(l.fields)+len(extra))
+	emit(level, msg, append(append(fields, l.fields...), extra...))
+}
+
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	if shouldLog(DEBUG) {
+		l.emit(DEBUG, fmt.Sprintf(format, v...), nil)
+	}
+}
+
+func (l *Logger) DebugKV(msg string, keysAndValues ...interface{}) {
+	if shouldLog(DEBUG) {
+		l.emit(DEBUG, msg, kvFields(keysAndValues))
+	}
+}
+
+func (l *Logger) Infof(format string, v ...interface{}) {
+	if shouldLog(INFO) {
+		l.emit(INFO, fmt.Sprintf(format, v...), nil)
+	}
+}
+
+func (l *Logger) InfoKV(msg string, keysAndValues ...interface{}) {
+	if shouldLog(INFO) {
+		l.emit(INFO, msg, kvFields(keysAndValues))
+	}
+}
+
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	if shouldLog(WARN) {
+		l.emit(WARN, fmt.Sprintf(format, v...), nil)
+	}
+}
+
+func (l *Logger) WarnKV(msg string, keysAndValues ...interface{}) {
+	if shouldLog(WARN) {
+		l.emit(WARN, msg, kvFields(keysAndValues))
+	}
+}
+
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	if shouldLog(ERROR) {
+		l.emit(ERROR, fmt.Sprintf(format, v...), nil)
+	}
+}
+
+func (l *Logger) ErrorKV(msg string, keysAndValues ...interface{}) {
+	if shouldLog(ERROR) {
+		l.emit(ERROR, msg, kvFields(keysAndValues))
+	}
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format names a record encoding.
+type Format string
+
+const (
+	// FormatText is the classic "INFO: 2006/01/02 15:04:05 message key=value" line.
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per line.
+	FormatJSON Format = "json"
+	// FormatLogfmt writes one line of space-separated key=value pairs.
+	FormatLogfmt Format = "logfmt"
+	// FormatMsgpack writes one MessagePack map per record. Records are
+	// self-delimiting, so they are written back to back without a separator.
+	FormatMsgpack Format = "msgpack"
+)
+
+func (f Format) valid() bool {
+	switch f {
+	case FormatText, FormatJSON, FormatLogfmt, FormatMsgpack:
+		return true
+	}
+	return false
+}
+
+// Encoder serializes a record, including any trailing delimiter.
+type Encoder interface {
+	Encode(r Record) []byte
+}
+
+// newEncoder builds the encoder for format, tagging records with label if set.
+func newEncoder(format Format, label string) Encoder {
+	switch format {
+	case FormatJSON:
+		return jsonEncoder{label: label}
+	case FormatLogfmt:
+		return logfmtEncoder{label: label}
+	case FormatMsgpack:
+		return msgpackEncoder{label: label}
+	default:
+		prefix := ""
+		if label != "" {
+			prefix = "[" + label + "] "
+		}
+		return textEncoder{prefix: prefix}
+	}
+}
+
+// textTimeFormat matches the standard library's log.LstdFlags.
+const textTimeFormat = "2006/01/02 15:04:05"
+
+type textEncoder struct {
+	prefix string
+}
+
+func (e textEncoder) Encode(r Record) []byte {
+	var b strings.Builder
+	b.WriteString(e.prefix)
+	b.WriteString(r.Level.String())
+	b.WriteString(": ")
+	b.WriteString(r.Time.Format(textTimeFormat))
+	b.WriteByte(' ')
+	b.WriteString(r.Message)
+	for _, f := range r.Fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(quoteIfNeeded(formatValue(f.Value)))
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+type jsonEncoder struct {
+	label string
+}
+
+func (e jsonEncoder) Encode(r Record) []byte {
+	buf := make([]byte, 0, 128)
+	buf = append(buf, `{"level":`...)
+	buf = strconv.AppendQuote(buf, r.Level.String())
+	buf = append(buf, `,"time":`...)
+	buf = strconv.AppendQuote(buf, r.Time.Format(time.RFC3339Nano))
+	if e.label != "" {
+		buf = append(buf, `,"label":`...)
+		buf = appendJSON(buf, e.label)
+	}
+	buf = append(buf, `,"msg":`...)
+	buf = appendJSON(buf, r.Message)
+	for _, f := range r.Fields {
+		buf = append(buf, ',')
+		buf = appendJSON(buf, f.Key)
+		buf = append(buf, ':')
+		buf = appendJSON(buf, jsonValue(f.Value))
+	}
+	buf = append(buf, "}\n"...)
+	return buf
+}
+
+type logfmtEncoder struct {
+	label string
+}
+
+func (e logfmtEncoder) Encode(r Record) []byte {
+	var b strings.Builder
+	b.WriteString("time=")
+	b.WriteString(r.Time.Format(time.RFC3339Nano))
+	b.WriteString(" level=")
+	b.WriteString(strings.ToLower(r.Level.String()))
+	if e.label != "" {
+		b.WriteString(" label=")
+		b.WriteString(quoteIfNeeded(e.label))
+	}
+	b.WriteString(" msg=")
+	b.WriteString(quoteIfNeeded(r.Message))
+	for _, f := range r.Fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(quoteIfNeeded(formatValue(f.Value)))
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+// appendJSON appends the JSON encoding of v, falling back to its string form.
+func appendJSON(buf []byte, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return append(buf, data...)
+}
+
+// jsonValue keeps values JSON understands natively and stringifies the rest
+// (errors, addresses, big numbers, ...) the way the text format prints them.
+func jsonValue(v interface{}) interface{} {
+	switch v.(type) {
+	case nil, bool, string,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v
+	}
+	return formatValue(v)
+}
+
+// formatValue renders a field value as text.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// quoteIfNeeded quotes s when it is empty or holds spaces, quotes or '='.
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
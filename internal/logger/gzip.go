@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultGzipFlushInterval is how often a GzipSink flushes what it has
+// compressed, bounding what a crash can lose.
+const DefaultGzipFlushInterval = 5 * time.Second
+
+// GzipSink is an output sink that gzip-compresses records into an
+// underlying writer, such as a log file. Writes are serialized through the
+// compressor, which is flushed every interval so the file stays readable up
+// to the last flush; Close writes the gzip trailer and must be called before
+// exiting. Appending to an existing file adds a gzip member, which gzip
+// readers decode as a continuation.
+type GzipSink struct {
+	mu        sync.Mutex
+	zw        *gzip.Writer
+	dst       io.WriteCloser
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewGzipSink compresses into w, flushing every interval (when > 0).
+func NewGzipSink(w io.WriteCloser, interval time.Duration) *GzipSink {
+	s := &GzipSink{
+		zw:   gzip.NewWriter(w),
+		dst:  w,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go s.flushLoop(interval)
+	return s
+}
+
+func (s *GzipSink) flushLoop(interval time.Duration) {
+	defer close(s.done)
+	if interval <= 0 {
+		<-s.stop
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.Flush()
+		}
+	}
+}
+
+// Write compresses p.
+func (s *GzipSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.zw.Write(p)
+}
+
+// Flush writes out what has been compressed so far.
+func (s *GzipSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.zw.Flush()
+}
+
+// Close stops the periodic flushes, completes the gzip stream and closes
+// the underlying writer. Records written afterwards fail; closing again
+// returns the first result.
+func (s *GzipSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.closeErr = s.zw.Close()
+		if err := s.dst.Close(); s.closeErr == nil {
+			s.closeErr = err
+		}
+	})
+	return s.closeErr
+}
@@ -1,10 +1,12 @@
 package logger
 
 import (
+	"bytes"
+	"fmt"
 	"io"
-	"log"
 	"os"
 	"sync"
+	"time"
 )
 
 // Level type as before
@@ -39,31 +41,141 @@ func (l Level) String() string {
 var (
     mu       sync.RWMutex
     minLevel = INFO
-    // Individual loggers
-    debugLogger *log.Logger
-    infoLogger  *log.Logger
-    warnLogger  *log.Logger
-    errorLogger *log.Logger
+    // Console writers: DEBUG, INFO and WARN go to stdout, ERROR to stderr.
+    stdout io.Writer = os.Stdout
+    stderr io.Writer = os.Stderr
+    // Extra sinks every level fans out to (files, syslog, ...).
+    sinks []io.Writer
+    // Resolved output of each level: its console writer plus the sinks.
+    outputs [ERROR + 1]io.Writer
+    // Encoding settings; the encoder is rebuilt only when one of them changes.
+    format  = FormatText
+    label   string
+    encoder Encoder = newEncoder(FormatText, "")
+
+    // writeMu serializes writes so records from concurrent goroutines never interleave.
+    writeMu sync.Mutex
 )
 
+// Field is a key/value pair attached to a log record.
+type Field struct {
+    Key   string
+    Value interface{}
+}
+
+// Record is a single log event as handed to the Encoder.
+type Record struct {
+    Level   Level
+    Time    time.Time
+    Message string
+    Fields  []Field
+}
+
 func init() {
     // By default, write DEBUG, INFO, WARN to stdout; ERROR to stderr.
-    // You can customize flags here (e.g. log.LstdFlags or log.Lshortfile|log.LstdFlags).
-    debugLogger = log.New(os.Stdout, "DEBUG: ", log.LstdFlags)
-    infoLogger = log.New(os.Stdout, "INFO: ", log.LstdFlags)
-    warnLogger = log.New(os.Stdout, "WARN: ", log.LstdFlags)
-    errorLogger = log.New(os.Stderr, "ERROR: ", log.LstdFlags)
+    applyOutputs()
 }
 
 // SetOutput allows directing all levels to a given writer.
-// If you want different outputs per level, you could set them individually.
+// It replaces the console writers and drops any sink added with AddOutput.
 func SetOutput(w io.Writer) {
     mu.Lock()
     defer mu.Unlock()
-    debugLogger.SetOutput(w)
-    infoLogger.SetOutput(w)
-    warnLogger.SetOutput(w)
-    errorLogger.SetOutput(w)
+    stdout = w
+    stderr = w
+    sinks = nil
+    applyOutputs()
+}
+
+// AddOutput adds a sink that receives every level in addition to the
+// current outputs, e.g. a log file next to the console.
+func AddOutput(w io.Writer) {
+    mu.Lock()
+    defer mu.Unlock()
+    sinks = append(sinks, w)
+    applyOutputs()
+}
+
+// SuspendConsole holds back the console records, e.g. while a full-screen
+// display owns the terminal; the sinks added with AddOutput keep receiving
+// every record. The returned function restores the console and writes the
+// records held back to its stdout writer, in order.
+func SuspendConsole() (resume func()) {
+    mu.Lock()
+    defer mu.Unlock()
+    out, errOut := stdout, stderr
+    held := &heldWriter{out: out}
+    stdout, stderr = held, held
+    applyOutputs()
+    return func() {
+        mu.Lock()
+        defer mu.Unlock()
+        writeMu.Lock()
+        defer writeMu.Unlock()
+        stdout, stderr = out, errOut
+        applyOutputs()
+        held.release()
+    }
+}
+
+// heldWriter buffers what is written to it until release, then writes it
+// out and passes later writes, from records already on their way, straight
+// through. Writes are serialized by writeMu.
+type heldWriter struct {
+    buf      bytes.Buffer
+    out      io.Writer
+    released bool
+}
+
+func (w *heldWriter) Write(p []byte) (int, error) {
+    if w.released {
+        return w.out.Write(p)
+    }
+    return w.buf.Write(p)
+}
+
+func (w *heldWriter) release() {
+    w.released = true
+    w.out.Write(w.buf.Bytes())
+    w.buf.Reset()
+}
+
+// applyOutputs points each level at its console writer plus the sinks.
+// Callers must hold mu.
+func applyOutputs() {
+    outputs[DEBUG] = fanOut(stdout)
+    outputs[INFO] = fanOut(stdout)
+    outputs[WARN] = fanOut(stdout)
+    outputs[ERROR] = fanOut(stderr)
+}
+
+func fanOut(console io.Writer) io.Writer {
+    if len(sinks) == 0 {
+        return console
+    }
+    return io.MultiWriter(append([]io.Writer{console}, sinks...)...)
+}
+
+// SetFormat selects how records are encoded: FormatText (default), FormatJSON,
+// FormatLogfmt or FormatMsgpack. Call it once at startup, before logging concurrently.
+func SetFormat(f Format) error {
+    if !f.valid() {
+        return fmt.Errorf("unknown log format %q", f)
+    }
+    mu.Lock()
+    defer mu.Unlock()
+    format = f
+    encoder = newEncoder(format, label)
+    return nil
+}
+
+// SetLabel tags every record with label: text lines are prefixed with
+// "[label] ", structured formats get a "label" field. An empty label removes it.
+func SetLabel(l string) {
+    mu.Lock()
+    defer mu.Unlock()
+    label = l
+    encoder = newEncoder(format, label)
 }
 
 // SetMinLevel sets the minimum log level globally.
@@ -81,47 +193,120 @@ func shouldLog(l Level) bool {
     return l >= minLevel
 }
 
+// emit encodes a record and writes it to the outputs of its level.
+func emit(l Level, msg string, fields []Field) {
+    mu.RLock()
+    enc := encoder
+    out := outputs[l]
+    buf := ring
+    mu.RUnlock()
+
+    r := Record{
+        Level:   l,
+        Time:    time.Now(),
+        Message: msg,
+        Fields:  fields,
+    }
+    if buf != nil {
+        buf.add(r)
+    }
+    data := enc.Encode(r)
+
+    writeMu.Lock()
+    defer writeMu.Unlock()
+    out.Write(data)
+}
+
+// kvFields turns alternating keys and values into fields. A trailing key
+// without a value is kept with a nil value.
+func kvFields(keysAndValues []interface{}) []Field {
+    fields := make([]Field, 0, (len(keysAndValues)+1)/2)
+    for i := 0; i < len(keysAndValues); i += 2 {
+        key := fmt.Sprint(keysAndValues[i])
+        var value interface{}
+        if i+1 < len(keysAndValues) {
+            value = keysAndValues[i+1]
+        }
+        fields = append(fields, Field{Key: key, Value: value})
+    }
+    return fields
+}
+
+// sprintln formats like log.Println without the trailing newline.
+func sprintln(v ...interface{}) string {
+    s := fmt.Sprintln(v...)
+    return s[:len(s)-1]
+}
+
 // Public functions:
 func Debug(v ...interface{}) {
     if shouldLog(DEBUG) {
-        debugLogger.Println(v...)
+        emit(DEBUG, sprintln(v...), nil)
     }
 }
 func Debugf(format string, v ...interface{}) {
     if shouldLog(DEBUG) {
-        debugLogger.Printf(format, v...)
+        emit(DEBUG, fmt.Sprintf(format, v...), nil)
+    }
+}
+
+// DebugKV logs msg with structured key/value fields, e.g. DebugKV("sent", "nonce", 4).
+func DebugKV(msg string, keysAndValues ...interface{}) {
+    if shouldLog(DEBUG) {
+        emit(DEBUG, msg, kvFields(keysAndValues))
     }
 }
 
 func Info(v ...interface{}) {
     if shouldLog(INFO) {
-        infoLogger.Println(v...)
+        emit(INFO, sprintln(v...), nil)
     }
 }
 func Infof(format string, v ...interface{}) {
     if shouldLog(INFO) {
-        infoLogger.Printf(format, v...)
+        emit(INFO, fmt.Sprintf(format, v...), nil)
+    }
+}
+
+// InfoKV logs msg with structured key/value fields.
+func InfoKV(msg string, keysAndValues ...interface{}) {
+    if shouldLog(INFO) {
+        emit(INFO, msg, kvFields(keysAndValues))
     }
 }
 
 func Warn(v ...interface{}) {
     if shouldLog(WARN) {
-        warnLogger.Println(v...)
+        emit(WARN, sprintln(v...), nil)
     }
 }
 func Warnf(format string, v ...interface{}) {
     if shouldLog(WARN) {
-        warnLogger.Printf(format, v...)
+        emit(WARN, fmt.Sprintf(format, v...), nil)
+    }
+}
+
+// WarnKV logs msg with structured key/value fields.
+func WarnKV(msg string, keysAndValues ...interface{}) {
+    if shouldLog(WARN) {
+        emit(WARN, msg, kvFields(keysAndValues))
     }
 }
 
 func Error(v ...interface{}) {
     if shouldLog(ERROR) {
-        errorLogger.Println(v...)
+        emit(ERROR, sprintln(v...), nil)
     }
 }
 func Errorf(format string, v ...interface{}) {
     if shouldLog(ERROR) {
-        errorLogger.Printf(format, v...)
+        emit(ERROR, fmt.Sprintf(format, v...), nil)
+    }
+}
+
+// ErrorKV logs msg with structured key/value fields.
+func ErrorKV(msg string, keysAndValues ...interface{}) {
+    if shouldLog(ERROR) {
+        emit(ERROR, msg, kvFields(keysAndValues))
     }
 }
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// msgpackEncoder writes each record as a MessagePack map with the keys
+// level, time (RFC 3339), msg, label (if set) and one key per field.
+// Only the subset of the format needed for log records is implemented.
+type msgpackEncoder struct {
+	label string
+}
+
+func (e msgpackEncoder) Encode(r Record) []byte {
+	entries := 3 + len(r.Fields)
+	if e.label != "" {
+		entries++
+	}
+
+	buf := make([]byte, 0, 128)
+	buf = appendMapHeader(buf, entries)
+	buf = appendString(appendString(buf, "level"), r.Level.String())
+	buf = appendString(appendString(buf, "time"), r.Time.Format(time.RFC3339Nano))
+	if e.label != "" {
+		buf = appendString(appendString(buf, "label"), e.label)
+	}
+	buf = appendString(appendString(buf, "msg"), r.Message)
+	for _, f := range r.Fields {
+		buf = appendValue(appendString(buf, f.Key), f.Value)
+	}
+	return buf
+}
+
+func appendMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, 0xdf), uint32(n))
+	}
+}
+
+func appendString(buf []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+	}
+	return append(buf, s...)
+}
+
+func appendInt(buf []byte, v int64) []byte {
+	if v >= 0 {
+		return appendUint(buf, uint64(v))
+	}
+	if v >= -32 {
+		return append(buf, byte(v))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(v))
+}
+
+func appendUint(buf []byte, v uint64) []byte {
+	if v < 128 {
+		return append(buf, byte(v))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xcf), v)
+}
+
+// appendValue encodes numbers, booleans and nil natively and everything else as
+// the same string the text format prints.
+func appendValue(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, 0xc0)
+	case bool:
+		if v {
+			return append(buf, 0xc3)
+		}
+		return append(buf, 0xc2)
+	case int:
+		return appendInt(buf, int64(v))
+	case int8:
+		return appendInt(buf, int64(v))
+	case int16:
+		return appendInt(buf, int64(v))
+	case int32:
+		return appendInt(buf, int64(v))
+	case int64:
+		return appendInt(buf, v)
+	case uint:
+		return appendUint(buf, uint64(v))
+	case uint8:
+		return appendUint(buf, uint64(v))
+	case uint16:
+		return appendUint(buf, uint64(v))
+	case uint32:
+		return appendUint(buf, uint64(v))
+	case uint64:
+		return appendUint(buf, v)
+	case float32:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(float64(v)))
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(v))
+	}
+	return appendString(buf, formatValue(v))
+}
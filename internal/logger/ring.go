@@ -0,0 +1,61 @@
+package logger
+
+import "sync"
+
+// ringBuffer keeps the last len(records) records, overwriting the oldest.
+type ringBuffer struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+func (b *ringBuffer) add(r Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.records[b.next] = r
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// snapshot returns the retained records, oldest first.
+func (b *ringBuffer) snapshot() []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.full {
+		return append([]Record(nil), b.records[:b.next]...)
+	}
+	out := make([]Record, 0, len(b.records))
+	out = append(out, b.records[b.next:]...)
+	return append(out, b.records[:b.next]...)
+}
+
+// ring retains recent records for RecentLogs; nil when disabled.
+var ring *ringBuffer
+
+// SetRingBuffer keeps the last capacity records in memory, in addition to the
+// outputs, for RecentLogs. Records below the minimum level are not kept.
+// A capacity <= 0 disables the buffer and drops what it held.
+func SetRingBuffer(capacity int) {
+	mu.Lock()
+	defer mu.Unlock()
+	if capacity <= 0 {
+		ring = nil
+		return
+	}
+	ring = &ringBuffer{records: make([]Record, capacity)}
+}
+
+// RecentLogs returns the records held by the ring buffer, oldest first,
+// or nil when SetRingBuffer has not enabled it.
+func RecentLogs() []Record {
+	mu.RLock()
+	r := ring
+	mu.RUnlock()
+	if r == nil {
+		return nil
+	}
+	return r.snapshot()
+}
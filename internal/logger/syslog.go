@@ -0,0 +1,19 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// SetSyslog adds the local syslog daemon as an output sink, tagging every
+// message with tag. Console output is kept.
+func SetSyslog(tag string) error {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	AddOutput(w)
+	return nil
+}
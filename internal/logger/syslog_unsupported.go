@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package logger
+
+import "errors"
+
+// SetSyslog is not available on this platform.
+func SetSyslog(tag string) error {
+	return errors.New("syslog is not supported on this platform")
+}
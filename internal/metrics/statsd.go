@@ -0,0 +1,68 @@
+// Package metrics emits the counters and timers of a run to a StatsD server.
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Statsd sends counters and timers to a StatsD server over UDP. Sends are
+// fire and forget: a lost packet or an unreachable server never slows a run.
+// A nil *Statsd discards everything, so callers need no checks when metrics
+// are disabled.
+type Statsd struct {
+	conn   net.Conn
+	addr   string
+	prefix string
+}
+
+// DialStatsd returns a client sending to the StatsD server at addr
+// ("host:port"), with every metric name prefixed by prefix and a dot.
+func DialStatsd(addr, prefix string) (*Statsd, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open StatsD connection to %s: %w", addr, err)
+	}
+	return &Statsd{conn: conn, addr: addr, prefix: prefix}, nil
+}
+
+// Count adds n to the counter name.
+func (s *Statsd) Count(name string, n int64) {
+	if s == nil {
+		return
+	}
+	s.send(name, strconv.FormatInt(n, 10), "c")
+}
+
+// Timing records a duration, in milliseconds, under the timer name.
+func (s *Statsd) Timing(name string, d time.Duration) {
+	if s == nil {
+		return
+	}
+	ms := float64(d) / float64(time.Millisecond)
+	s.send(name, strconv.FormatFloat(ms, 'f', -1, 64), "ms")
+}
+
+// MarshalText encodes the client as the server address it sends to.
+func (s *Statsd) MarshalText() ([]byte, error) {
+	return []byte(s.addr), nil
+}
+
+// Close releases the connection.
+func (s *Statsd) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// send writes one "prefix.name:value|type" datagram. Write errors are dropped:
+// UDP reports at best that an earlier datagram went nowhere.
+func (s *Statsd) send(name, value, kind string) {
+	if s.prefix != "" {
+		name = s.prefix + "." + name
+	}
+	s.conn.Write([]byte(name + ":" + value + "|" + kind))
+}
@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// EthBackend is the subset of the Ethereum JSON-RPC API the tool uses.
+// *ethclient.Client implements it; NullBackend stands in for it offline.
+type EthBackend interface {
+	NetworkID(ctx context.Context) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	Close()
+}
+
+var _ EthBackend = (*ethclient.Client)(nil)
+
+// Dial connects to url with opts, or returns a NullBackend when url is NullURL.
+func Dial(ctx context.Context, url string, opts DialOptions) (EthBackend, error) {
+	if url == NullURL {
+		return NewNullBackend(), nil
+	}
+	client, err := gethrpc.DialOptions(ctx, url, opts.clientOptions(url)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var backend EthBackend = ethclient.NewClient(client)
+	if timeout := opts.Endpoints[url].Timeout; timeout > 0 {
+		backend = timeoutBackend{EthBackend: backend, timeout: timeout}
+	}
+	return backend, nil
+}
@@ -0,0 +1,28 @@
+package rpc
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// ParseBlockNumber translates a user supplied block reference into the form
+// ethclient state queries such as BalanceAt expect: "latest" (or "") becomes nil,
+// "pending" becomes the pending block tag and anything else must be a decimal
+// or 0x-prefixed block number.
+func ParseBlockNumber(block string) (*big.Int, error) {
+	switch strings.ToLower(strings.TrimSpace(block)) {
+	case "", "latest":
+		return nil, nil
+	case "pending":
+		return big.NewInt(int64(gethrpc.PendingBlockNumber)), nil
+	}
+
+	number, ok := new(big.Int).SetString(strings.TrimSpace(block), 0)
+	if !ok || number.Sign() < 0 {
+		return nil, fmt.Errorf("invalid block %q: want latest, pending or a block number", block)
+	}
+	return number, nil
+}
@@ -0,0 +1,58 @@
+package rpc
+
+import "math/big"
+
+// Chain describes a well-known network.
+type Chain struct {
+	Name string
+	// Mainnet marks production networks where transactions cost real money.
+	Mainnet bool
+}
+
+// knownChains maps chain IDs to the networks they identify.
+var knownChains = map[uint64]Chain{
+	1:        {Name: "mainnet", Mainnet: true},
+	10:       {Name: "optimism", Mainnet: true},
+	56:       {Name: "bsc", Mainnet: true},
+	100:      {Name: "gnosis", Mainnet: true},
+	137:      {Name: "polygon", Mainnet: true},
+	324:      {Name: "zksync", Mainnet: true},
+	8453:     {Name: "base", Mainnet: true},
+	42161:    {Name: "arbitrum", Mainnet: true},
+	43114:    {Name: "avalanche", Mainnet: true},
+	59144:    {Name: "linea", Mainnet: true},
+	97:       {Name: "bsc-testnet"},
+	1337:     {Name: "dev"},
+	17000:    {Name: "holesky"},
+	31337:    {Name: "hardhat"},
+	80002:    {Name: "polygon-amoy"},
+	84532:    {Name: "base-sepolia"},
+	421614:   {Name: "arbitrum-sepolia"},
+	560048:   {Name: "hoodi"},
+	11155111: {Name: "sepolia"},
+	11155420: {Name: "optimism-sepolia"},
+}
+
+// LookupChain returns the well-known network with the given chain ID.
+// ok is false for unknown or nil IDs.
+func LookupChain(chainID *big.Int) (chain Chain, ok bool) {
+	if chainID == nil || !chainID.IsUint64() {
+		return Chain{}, false
+	}
+	chain, ok = knownChains[chainID.Uint64()]
+	return chain, ok
+}
+
+// ChainName returns the name of a well-known chain ID, or "unknown".
+func ChainName(chainID *big.Int) string {
+	if chain, ok := LookupChain(chainID); ok {
+		return chain.Name
+	}
+	return "unknown"
+}
+
+// IsMainnet reports whether chainID identifies a known production network.
+func IsMainnet(chainID *big.Int) bool {
+	chain, _ := LookupChain(chainID)
+	return chain.Mainnet
+}
@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// EndpointConfig holds the settings of one endpoint, keyed by its URL in
+// DialOptions.Endpoints.
+type EndpointConfig struct {
+	// Timeout bounds every request to the endpoint; 0 leaves it to the caller's context.
+	Timeout time.Duration
+	// UserAgent overrides DialOptions.ClientName for this endpoint.
+	UserAgent string
+	// Headers are sent with every request, e.g. provider API keys.
+	Headers map[string]string
+}
+
+// LoadEndpointConfigs reads per-endpoint settings from a JSON file mapping URLs
+// to {"timeout": "5s", "user_agent": "...", "headers": {"Name": "value"}}.
+func LoadEndpointConfigs(path string) (map[string]EndpointConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read endpoint config: %w", err)
+	}
+
+	var raw map[string]struct {
+		Timeout   string            `json:"timeout"`
+		UserAgent string            `json:"user_agent"`
+		Headers   map[string]string `json:"headers"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse endpoint config %s: %w", path, err)
+	}
+
+	configs := make(map[string]EndpointConfig, len(raw))
+	for url, c := range raw {
+		config := EndpointConfig{UserAgent: c.UserAgent, Headers: c.Headers}
+		if c.Timeout != "" {
+			if config.Timeout, err = time.ParseDuration(c.Timeout); err != nil || config.Timeout < 0 {
+				return nil, fmt.Errorf("endpoint config: invalid timeout %q for %s", c.Timeout, url)
+			}
+		}
+		configs[url] = config
+	}
+	return configs, nil
+}
+
+// timeoutBackend bounds every call to the wrapped backend by timeout.
+type timeoutBackend struct {
+	EthBackend
+	timeout time.Duration
+}
+
+func (b timeoutBackend) NetworkID(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.NetworkID(ctx)
+}
+
+func (b timeoutBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.PendingNonceAt(ctx, account)
+}
+
+func (b timeoutBackend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.NonceAt(ctx, account, blockNumber)
+}
+
+func (b timeoutBackend) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.BalanceAt(ctx, account, blockNumber)
+}
+
+func (b timeoutBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.HeaderByNumber(ctx, number)
+}
+
+func (b timeoutBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.EstimateGas(ctx, msg)
+}
+
+func (b timeoutBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.SuggestGasTipCap(ctx)
+}
+
+func (b timeoutBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.SuggestGasPrice(ctx)
+}
+
+func (b timeoutBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+}
+
+func (b timeoutBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.SendTransaction(ctx, tx)
+}
+
+func (b timeoutBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.TransactionReceipt(ctx, txHash)
+}
+
+func (b timeoutBackend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.TransactionByHash(ctx, hash)
+}
+
+func (b timeoutBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	return b.EthBackend.CallContract(ctx, msg, blockNumber)
+}
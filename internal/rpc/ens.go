@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistry is the address of the ENS registry, the same on mainnet and
+// the testnets ENS is deployed to.
+var ENSRegistry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+var (
+	ensResolverSelector = crypto.Keccak256([]byte("resolver(bytes32)"))[:4]
+	ensAddrSelector     = crypto.Keccak256([]byte("addr(bytes32)"))[:4]
+
+	ensCacheMu sync.Mutex
+	ensCache   = make(map[string]common.Address)
+)
+
+// IsENSName reports whether s looks like an ENS name (dot-separated labels,
+// such as vitalik.eth) rather than a hex address.
+func IsENSName(s string) bool {
+	if common.IsHexAddress(s) || !strings.Contains(s, ".") {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveAddress returns the address s names: s itself when it is not an
+// ENS name, which then has to be a hex address, or else ResolveENS(client, s).
+func ResolveAddress(client EthBackend, s string) (common.Address, error) {
+	if !IsENSName(s) {
+		if !common.IsHexAddress(s) {
+			return common.Address{}, fmt.Errorf("%q is neither a hex address nor an ENS name", s)
+		}
+		return common.HexToAddress(s), nil
+	}
+	return ResolveENS(client, s)
+}
+
+// ResolveENS looks name up in the ENS registry through client: it asks the
+// registry for the name's resolver, then the resolver for its address.
+// Names are lowercased, not fully normalized. Successful lookups are cached
+// for the life of the process.
+func ResolveENS(client EthBackend, name string) (common.Address, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	ensCacheMu.Lock()
+	address, ok := ensCache[name]
+	ensCacheMu.Unlock()
+	if ok {
+		return address, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	node := ENSNamehash(name)
+	resolver, err := ensCallAddress(ctx, client, ENSRegistry, ensResolverSelector, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("ENS name %s: failed to get resolver: %w", name, err)
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("ENS name %s has no resolver", name)
+	}
+
+	address, err = ensCallAddress(ctx, client, resolver, ensAddrSelector, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("ENS name %s: failed to resolve address: %w", name, err)
+	}
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("ENS name %s resolves to no address", name)
+	}
+
+	ensCacheMu.Lock()
+	ensCache[name] = address
+	ensCacheMu.Unlock()
+	return address, nil
+}
+
+// ENSNamehash computes the EIP-137 namehash of name.
+func ENSNamehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node[:], crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// ensCallAddress calls the function of contract with the given selector and a
+// single bytes32 argument, and decodes the address it returns.
+func ensCallAddress(ctx context.Context, client EthBackend, contract common.Address, selector []byte, node common.Hash) (common.Address, error) {
+	data := append(append([]byte{}, selector...), node[:]...)
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(out) < 32 {
+		return common.Address{}, nil
+	}
+	return common.BytesToAddress(out[12:32]), nil
+}
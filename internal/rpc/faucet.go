@@ -0,0 +1,148 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultFaucetTimeout bounds a faucet request when no timeout is given.
+	DefaultFaucetTimeout = 10 * time.Second
+	// DefaultFaucetRPS is how many requests per second a faucet is sent by
+	// default; public faucets commonly allow no more.
+	DefaultFaucetRPS = 1.0
+	// DefaultFaucetRetries is how many times a rate-limited or failing
+	// request is retried.
+	DefaultFaucetRetries = 3
+	// faucetRetryDelay is the pause before the first retry, doubled on each
+	// following one, unless the faucet answers with Retry-After.
+	faucetRetryDelay = 2 * time.Second
+)
+
+// errFaucetRetryable marks faucet failures worth another attempt: network
+// errors, 429 Too Many Requests and 5xx answers.
+var errFaucetRetryable = errors.New("faucet unavailable")
+
+// FaucetClient asks an HTTP faucet to fund addresses. Requests are POSTed as
+// {"address": "0x..."}; the faucet may answer with the hash of the funding
+// transaction as "tx_hash", "txHash" or "hash". Requests are spaced out to
+// the faucet's rate, and those it rejects as rate-limited or fails with a
+// server error are retried.
+type FaucetClient struct {
+	url     string
+	client  *http.Client
+	limiter *rate.Limiter
+	retries int
+}
+
+// NewFaucetClient returns a client for the faucet at url, sending at most
+// rps requests per second (DefaultFaucetRPS when <= 0), each giving up after
+// timeout (DefaultFaucetTimeout when <= 0).
+func NewFaucetClient(url string, rps float64, timeout time.Duration) *FaucetClient {
+	if rps <= 0 {
+		rps = DefaultFaucetRPS
+	}
+	if timeout <= 0 {
+		timeout = DefaultFaucetTimeout
+	}
+	return &FaucetClient{
+		url:     url,
+		client:  &http.Client{Timeout: timeout},
+		limiter: rate.NewLimiter(rate.Limit(rps), 1),
+		retries: DefaultFaucetRetries,
+	}
+}
+
+// URL returns the faucet endpoint.
+func (c *FaucetClient) URL() string {
+	return c.url
+}
+
+// MarshalText encodes the client as its endpoint.
+func (c *FaucetClient) MarshalText() ([]byte, error) {
+	return []byte(c.url), nil
+}
+
+// Fund asks the faucet to fund address and returns the hash of the funding
+// transaction, the zero hash when the faucet does not say. It is safe for
+// concurrent use; concurrent calls share the rate limit.
+func (c *FaucetClient) Fund(ctx context.Context, address common.Address) (common.Hash, error) {
+	delay := faucetRetryDelay
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return common.Hash{}, fmt.Errorf("faucet: %w", err)
+		}
+		hash, retryAfter, err := c.request(ctx, address)
+		if err == nil || !errors.Is(err, errFaucetRetryable) || attempt == c.retries {
+			return hash, err
+		}
+
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return common.Hash{}, fmt.Errorf("faucet: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// request makes one attempt at funding address. A rate-limited answer
+// returns how long its Retry-After header asks to wait, if it gives seconds.
+func (c *FaucetClient) request(ctx context.Context, address common.Address) (common.Hash, time.Duration, error) {
+	payload, _ := json.Marshal(map[string]string{"address": address.Hex()})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return common.Hash{}, 0, fmt.Errorf("faucet: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return common.Hash{}, 0, fmt.Errorf("%w: %v", errFaucetRetryable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return common.Hash{}, 0, fmt.Errorf("%w: failed to read response: %v", errFaucetRetryable, err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return common.Hash{}, retryAfter, fmt.Errorf("%w: %s: %s", errFaucetRetryable, resp.Status, bytes.TrimSpace(body))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return common.Hash{}, 0, fmt.Errorf("faucet: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	// Faucets answer in many shapes; only a hash under a common name is used.
+	var answer struct {
+		Snake string `json:"tx_hash"`
+		Camel string `json:"txHash"`
+		Hash  string `json:"hash"`
+	}
+	json.Unmarshal(body, &answer)
+	for _, h := range []string{answer.Snake, answer.Camel, answer.Hash} {
+		if b, err := hexutil.Decode(h); err == nil && len(b) == common.HashLength {
+			return common.BytesToHash(b), 0, nil
+		}
+	}
+	return common.Hash{}, 0, nil
+}
@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// SuggestTipFromFeeHistory asks the node for the priority fees paid at the given
+// percentile over the last `blocks` blocks (eth_feeHistory) and reduces them to a
+// single tip with TipFromFeeHistory.
+//
+// percentile: 0-100, e.g. 50 for the median tip paid in each block, 90 to outbid most of it.
+func SuggestTipFromFeeHistory(ctx context.Context, client EthBackend, blocks uint64, percentile float64) (*big.Int, error) {
+	if blocks == 0 {
+		return nil, errors.New("fee history block count must be > 0")
+	}
+	if percentile < 0 || percentile > 100 {
+		return nil, fmt.Errorf("tip percentile must be within [0, 100], got %v", percentile)
+	}
+
+	history, err := client.FeeHistory(ctx, blocks, nil, []float64{percentile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+
+	return TipFromFeeHistory(history)
+}
+
+// TipFromFeeHistory returns the median of the per-block rewards in history, using the
+// first requested percentile of every block. Empty blocks (no gas used) report a zero
+// reward that says nothing about the fee market, so they are skipped.
+//
+// Returns an error if the history holds no usable reward samples.
+func TipFromFeeHistory(history *ethereum.FeeHistory) (*big.Int, error) {
+	if history == nil {
+		return nil, errors.New("empty fee history")
+	}
+
+	rewards := make([]*big.Int, 0, len(history.Reward))
+	for i, blockRewards := range history.Reward {
+		if len(blockRewards) == 0 || blockRewards[0] == nil {
+			continue
+		}
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		rewards = append(rewards, blockRewards[0])
+	}
+
+	if len(rewards) == 0 {
+		return nil, errors.New("fee history contains no reward samples")
+	}
+
+	sort.Slice(rewards, func(i, j int) bool {
+		return rewards[i].Cmp(rewards[j]) < 0
+	})
+
+	mid := len(rewards) / 2
+	if len(rewards)%2 == 1 {
+		return new(big.Int).Set(rewards[mid]), nil
+	}
+
+	// Even sample count: average the two middle values.
+	median := new(big.Int).Add(rewards[mid-1], rewards[mid])
+	return median.Div(median, big.NewInt(2)), nil
+}
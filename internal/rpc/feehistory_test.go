@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// feeHistoryBackend answers eth_feeHistory from synthetic blocks: the tips
+// paid in each one, oldest first, an empty block having none. Like a node, it
+// reports the tip at the requested percentile of each block and a zero
+// reward for empty blocks. history, when set, is returned as is instead.
+type feeHistoryBackend struct {
+	*NullBackend
+	blocks  [][]int64
+	history *ethereum.FeeHistory
+	calls   int
+}
+
+func (b *feeHistoryBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	b.calls++
+	if b.history != nil {
+		return b.history, nil
+	}
+	history := &ethereum.FeeHistory{OldestBlock: new(big.Int)}
+	for _, tips := range b.blocks[len(b.blocks)-int(min(blockCount, uint64(len(b.blocks)))):] {
+		row := make([]*big.Int, len(rewardPercentiles))
+		ratio := 0.0
+		for i, p := range rewardPercentiles {
+			row[i] = big.NewInt(percentileOf(tips, p))
+		}
+		if len(tips) > 0 {
+			ratio = 0.5
+		}
+		history.Reward = append(history.Reward, row)
+		history.GasUsedRatio = append(history.GasUsedRatio, ratio)
+	}
+	return history, nil
+}
+
+// percentileOf returns the tip paid at percentile p of tips, nearest rank.
+func percentileOf(tips []int64, p float64) int64 {
+	if len(tips) == 0 {
+		return 0
+	}
+	sorted := slices.Sorted(slices.Values(tips))
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+func TestSuggestTipFromFeeHistory(t *testing.T) {
+	// Three busy blocks whose tips spread differently around their median.
+	busy := [][]int64{
+		{1, 2, 3, 4, 5},
+		{10, 20, 30, 40, 50},
+		{2, 4, 6, 8, 100},
+	}
+	for _, c := range []struct {
+		name       string
+		backend    *feeHistoryBackend
+		blocks     uint64
+		percentile float64
+		want       int64 // -1 for an error
+		noCall     bool  // the node must not be asked at all
+	}{
+		{name: "median of block medians", backend: &feeHistoryBackend{blocks: busy}, blocks: 3, percentile: 50, want: 6},
+		{name: "percentile 0 takes each block's lowest tip", backend: &feeHistoryBackend{blocks: busy}, blocks: 3, percentile: 0, want: 2},
+		{name: "percentile 100 takes each block's highest tip", backend: &feeHistoryBackend{blocks: busy}, blocks: 3, percentile: 100, want: 50},
+		{name: "only the last blocks asked for", backend: &feeHistoryBackend{blocks: busy}, blocks: 1, percentile: 50, want: 6},
+		{name: "even block count averages the middle two", backend: &feeHistoryBackend{blocks: busy[:2]}, blocks: 2, percentile: 90, want: 27},
+		{
+			name:    "empty blocks skipped",
+			backend: &feeHistoryBackend{blocks: [][]int64{nil, {7, 9}, nil, {3}, nil}},
+			blocks:  5, percentile: 100, want: 6,
+		},
+		{name: "only empty blocks", backend: &feeHistoryBackend{blocks: [][]int64{nil, nil}}, blocks: 2, percentile: 50, want: -1},
+		{
+			name: "nil and missing reward rows skipped",
+			backend: &feeHistoryBackend{history: &ethereum.FeeHistory{
+				Reward:       [][]*big.Int{{big.NewInt(4)}, nil, {nil}, {}, {big.NewInt(8)}, {big.NewInt(30)}},
+				GasUsedRatio: []float64{0.5, 0.5, 0.5, 0.5, 0.5},
+			}},
+			blocks: 6, percentile: 50, want: 8,
+		},
+		{
+			name:    "no reward rows",
+			backend: &feeHistoryBackend{history: &ethereum.FeeHistory{GasUsedRatio: []float64{0.5, 0.5}}},
+			blocks:  2, percentile: 50, want: -1,
+		},
+		{name: "percentile below 0", backend: &feeHistoryBackend{blocks: busy}, blocks: 3, percentile: -1, want: -1, noCall: true},
+		{name: "percentile above 100", backend: &feeHistoryBackend{blocks: busy}, blocks: 3, percentile: 100.5, want: -1, noCall: true},
+		{name: "no blocks", backend: &feeHistoryBackend{blocks: busy}, blocks: 0, percentile: 50, want: -1, noCall: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			c.backend.NullBackend = NewNullBackend()
+			tip, err := SuggestTipFromFeeHistory(context.Background(), c.backend, c.blocks, c.percentile)
+			switch {
+			case c.want < 0 && err == nil:
+				t.Fatalf("tip = %v, want an error", tip)
+			case c.want >= 0 && err != nil:
+				t.Fatal(err)
+			case c.want >= 0 && tip.Cmp(big.NewInt(c.want)) != 0:
+				t.Errorf("tip = %v, want %d", tip, c.want)
+			}
+			if c.noCall && c.backend.calls != 0 {
+				t.Errorf("node asked for fee history %d times, want 0", c.backend.calls)
+			}
+		})
+	}
+}
+
+func TestTipFromFeeHistoryNil(t *testing.T) {
+	if _, err := TipFromFeeHistory(nil); err == nil {
+		t.Fatal("tip from a nil fee history")
+	}
+}
+
+func TestSuggestTipFromFeeHistoryError(t *testing.T) {
+	errNode := errors.New("method not found")
+	client := &failingFeeHistory{NullBackend: NewNullBackend(), err: errNode}
+	if _, err := SuggestTipFromFeeHistory(context.Background(), client, 3, 50); !errors.Is(err, errNode) {
+		t.Fatalf("error = %v, want it to wrap %v", err, errNode)
+	}
+}
+
+// failingFeeHistory is a NullBackend whose eth_feeHistory fails.
+type failingFeeHistory struct {
+	*NullBackend
+	err error
+}
+
+func (b *failingFeeHistory) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return nil, b.err
+}
@@ -0,0 +1,160 @@
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// NullURL selects the NullBackend in place of an RPC endpoint (-rpc-url null).
+const NullURL = "null"
+
+// Canned values answered by NullBackend.
+var (
+	NullChainID = big.NewInt(1337)
+	nullBaseFee = big.NewInt(1_000_000_000)
+	nullTipCap  = big.NewInt(1_000_000_000)
+	// 1000 ETH, enough for any offline run.
+	nullBalance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+)
+
+// NullBackend is an in-memory EthBackend that needs no network: it answers
+// fixed chain ID, balance and fees, accepts every transaction and reports it
+// mined successfully. Nonces follow the transactions it accepted, so the whole
+// signing and broadcast pipeline can be exercised offline.
+type NullBackend struct {
+	mu     sync.Mutex
+	nonces map[common.Address]uint64
+	sent   map[common.Hash]*types.Transaction
+	block  uint64
+}
+
+// NewNullBackend returns an empty NullBackend.
+func NewNullBackend() *NullBackend {
+	return &NullBackend{
+		nonces: make(map[common.Address]uint64),
+		sent:   make(map[common.Hash]*types.Transaction),
+	}
+}
+
+func (b *NullBackend) NetworkID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(NullChainID), nil
+}
+
+func (b *NullBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.nonces[account], nil
+}
+
+// NonceAt equals PendingNonceAt: every accepted transaction is mined at once.
+func (b *NullBackend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return b.PendingNonceAt(ctx, account)
+}
+
+func (b *NullBackend) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return new(big.Int).Set(nullBalance), nil
+}
+
+func (b *NullBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &types.Header{
+		Number:   new(big.Int).SetUint64(b.block),
+		GasLimit: 30_000_000,
+		BaseFee:  new(big.Int).Set(nullBaseFee),
+	}, nil
+}
+
+// EstimateGas charges the intrinsic cost: 21000 plus 16 per calldata byte.
+func (b *NullBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return 21000 + 16*uint64(len(msg.Data)), nil
+}
+
+func (b *NullBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(nullTipCap), nil
+}
+
+// SuggestGasPrice answers the base fee plus the tip, as a node past London does.
+func (b *NullBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Add(nullBaseFee, nullTipCap), nil
+}
+
+func (b *NullBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	history := &ethereum.FeeHistory{OldestBlock: new(big.Int)}
+	for i := uint64(0); i < blockCount; i++ {
+		reward := make([]*big.Int, len(rewardPercentiles))
+		for j := range reward {
+			reward[j] = new(big.Int).Set(nullTipCap)
+		}
+		history.Reward = append(history.Reward, reward)
+		history.BaseFee = append(history.BaseFee, new(big.Int).Set(nullBaseFee))
+		history.GasUsedRatio = append(history.GasUsedRatio, 0.5)
+	}
+	return history, nil
+}
+
+// SendTransaction accepts tx and advances the sender's nonce past it.
+func (b *NullBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	chainID := tx.ChainId()
+	if !tx.Protected() {
+		// A homestead transaction has no chain ID to pick a signer by.
+		chainID = nil
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent[tx.Hash()] = tx
+	if tx.Nonce() >= b.nonces[from] {
+		b.nonces[from] = tx.Nonce() + 1
+	}
+	b.block++
+	return nil
+}
+
+// TransactionReceipt reports every accepted transaction as mined successfully.
+func (b *NullBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tx, ok := b.sent[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return &types.Receipt{
+		Type:              tx.Type(),
+		Status:            types.ReceiptStatusSuccessful,
+		TxHash:            txHash,
+		GasUsed:           tx.Gas(),
+		EffectiveGasPrice: new(big.Int).Add(nullBaseFee, tx.GasTipCap()),
+		BlockNumber:       new(big.Int).SetUint64(b.block),
+	}, nil
+}
+
+// TransactionByHash finds every accepted transaction, already mined.
+func (b *NullBackend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tx, ok := b.sent[hash]
+	if !ok {
+		return nil, false, ethereum.NotFound
+	}
+	return tx, false, nil
+}
+
+// CallContract succeeds with no output: no call ever reverts.
+func (b *NullBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *NullBackend) Close() {}
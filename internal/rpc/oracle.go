@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// DefaultOracleTimeout bounds a gas oracle request when no timeout is given.
+const DefaultOracleTimeout = 3 * time.Second
+
+// OracleFees are the fee parameters a gas oracle answers with, in Wei.
+type OracleFees struct {
+	Tip     *big.Int
+	MaxFee  *big.Int
+	BaseFee *big.Int
+}
+
+// OracleClient fetches fee parameters from an external HTTP gas oracle that
+// answers GET requests with {"tip": ..., "maxFee": ..., "baseFee": ...} in Wei,
+// each given as a JSON number or a decimal string.
+type OracleClient struct {
+	url    string
+	client *http.Client
+}
+
+// NewOracleClient returns a client for the oracle at url whose requests give up
+// after timeout (DefaultOracleTimeout when <= 0).
+func NewOracleClient(url string, timeout time.Duration) *OracleClient {
+	if timeout <= 0 {
+		timeout = DefaultOracleTimeout
+	}
+	return &OracleClient{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// URL returns the oracle endpoint.
+func (c *OracleClient) URL() string {
+	return c.url
+}
+
+// MarshalText encodes the client as its endpoint.
+func (c *OracleClient) MarshalText() ([]byte, error) {
+	return []byte(c.url), nil
+}
+
+// Fees fetches the current fee parameters. It fails when the oracle is
+// unreachable, answers with a non-2xx status, or returns missing or
+// inconsistent values, so callers can fall back to the node.
+func (c *OracleClient) Fees(ctx context.Context) (*OracleFees, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gas oracle: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gas oracle: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("gas oracle: failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("gas oracle: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var raw struct {
+		Tip     json.RawMessage `json:"tip"`
+		MaxFee  json.RawMessage `json:"maxFee"`
+		BaseFee json.RawMessage `json:"baseFee"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("gas oracle: failed to parse response: %w", err)
+	}
+
+	var fees OracleFees
+	if fees.Tip, err = parseOracleWei("tip", raw.Tip); err != nil {
+		return nil, err
+	}
+	if fees.MaxFee, err = parseOracleWei("maxFee", raw.MaxFee); err != nil {
+		return nil, err
+	}
+	if fees.BaseFee, err = parseOracleWei("baseFee", raw.BaseFee); err != nil {
+		return nil, err
+	}
+	if fees.MaxFee.Cmp(fees.Tip) < 0 {
+		return nil, fmt.Errorf("gas oracle: maxFee %s is below tip %s", fees.MaxFee, fees.Tip)
+	}
+	return &fees, nil
+}
+
+// parseOracleWei decodes a non-negative Wei amount given as a JSON number or
+// decimal string. Numbers are read as text so large values keep their precision.
+func parseOracleWei(name string, raw json.RawMessage) (*big.Int, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("gas oracle: response has no %s", name)
+	}
+
+	text := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, fmt.Errorf("gas oracle: invalid %s: %w", name, err)
+		}
+	}
+
+	wei, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return nil, fmt.Errorf("gas oracle: invalid %s %s", name, raw)
+	}
+	if wei.Sign() < 0 {
+		return nil, fmt.Errorf("gas oracle: %s is negative", name)
+	}
+	return wei, nil
+}
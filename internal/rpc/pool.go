@@ -0,0 +1,153 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Endpoint is a connected JSON-RPC endpoint.
+type Endpoint struct {
+	URL    string
+	Client EthBackend
+}
+
+// RpcPool holds connections to one or more endpoints. The first endpoint is the
+// primary, used for state queries; Next spreads broadcasts over all of them.
+type RpcPool struct {
+	endpoints []*Endpoint
+	next      atomic.Uint64
+
+	// cumulative holds the running sums of the weights set by SetWeights,
+	// drawn against with rng; nil means round-robin.
+	mu         sync.Mutex
+	cumulative []float64
+	rng        *rand.Rand
+}
+
+// SplitURLs splits a comma-separated list of RPC URLs, dropping empty entries.
+func SplitURLs(list string) []string {
+	var urls []string
+	for _, u := range strings.Split(list, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// ParseWeights parses a comma-separated list of endpoint weights such as
+// "70,30", one per RPC URL in the same order.
+func ParseWeights(spec string) ([]float64, error) {
+	var (
+		weights []float64
+		total   float64
+	)
+	for _, field := range strings.Split(spec, ",") {
+		w, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || w < 0 || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("invalid RPC weight %q: must be a finite number >= 0", field)
+		}
+		weights = append(weights, w)
+		total += w
+	}
+	if total == 0 {
+		return nil, errors.New("RPC weights must not all be 0")
+	}
+	return weights, nil
+}
+
+// DialPool connects to every URL with opts (NullURL gives an offline NullBackend).
+// If any dial fails, the connections already made are closed and the error is returned.
+func DialPool(ctx context.Context, urls []string, opts DialOptions) (*RpcPool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no RPC URL given")
+	}
+
+	pool := &RpcPool{}
+	for _, url := range urls {
+		client, err := Dial(ctx, url, opts)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to connect to RPC %s: %w", url, err)
+		}
+		pool.endpoints = append(pool.endpoints, &Endpoint{URL: url, Client: client})
+	}
+	return pool, nil
+}
+
+// NewPool returns a pool over endpoints that are already connected, e.g.
+// stub backends in tests. It must be given at least one.
+func NewPool(endpoints ...*Endpoint) *RpcPool {
+	return &RpcPool{endpoints: endpoints}
+}
+
+// Primary returns the first endpoint.
+func (p *RpcPool) Primary() *Endpoint {
+	return p.endpoints[0]
+}
+
+// Endpoints returns every endpoint in the order they were given.
+func (p *RpcPool) Endpoints() []*Endpoint {
+	return p.endpoints
+}
+
+// Len returns the number of endpoints.
+func (p *RpcPool) Len() int {
+	return len(p.endpoints)
+}
+
+// SetWeights makes Next pick endpoints at random, drawn from rng, in
+// proportion to weights, one per endpoint (see ParseWeights).
+func (p *RpcPool) SetWeights(weights []float64, rng *rand.Rand) error {
+	if len(weights) != len(p.endpoints) {
+		return fmt.Errorf("%d RPC weights given for %d endpoints", len(weights), len(p.endpoints))
+	}
+	cumulative := make([]float64, len(weights))
+	var total float64
+	for i, w := range weights {
+		total += w
+		cumulative[i] = total
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cumulative = cumulative
+	p.rng = rng
+	return nil
+}
+
+// Next returns the endpoints in round-robin order, or at random by weight
+// after SetWeights. It is safe for concurrent use.
+func (p *RpcPool) Next() *Endpoint {
+	p.mu.Lock()
+	if p.cumulative != nil {
+		draw := p.rng.Float64() * p.cumulative[len(p.cumulative)-1]
+		p.mu.Unlock()
+		// The first endpoint whose running sum exceeds the draw; endpoints of
+		// weight 0 add nothing to the sum, so they are never picked.
+		i, _ := slices.BinarySearch(p.cumulative, draw)
+		for i < len(p.cumulative)-1 && p.cumulative[i] <= draw {
+			i++
+		}
+		return p.endpoints[i]
+	}
+	p.mu.Unlock()
+
+	i := p.next.Add(1) - 1
+	return p.endpoints[i%uint64(len(p.endpoints))]
+}
+
+// Close closes every connection.
+func (p *RpcPool) Close() {
+	for _, e := range p.endpoints {
+		e.Client.Close()
+	}
+}
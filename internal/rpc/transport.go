@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// DialOptions tune how endpoints are connected to.
+type DialOptions struct {
+	// ClientName, when set, is sent as the User-Agent header so providers can
+	// tell this tool's traffic apart.
+	ClientName string
+	// Trace logs the method and id of every outgoing JSON-RPC request at DEBUG
+	// level. Only HTTP endpoints can be traced.
+	Trace bool
+	// Endpoints holds per-endpoint settings by URL; endpoints not listed use the defaults.
+	Endpoints map[string]EndpointConfig
+}
+
+// clientOptions translates opts into go-ethereum client options for url.
+func (opts DialOptions) clientOptions(url string) []gethrpc.ClientOption {
+	var options []gethrpc.ClientOption
+	endpoint := opts.Endpoints[url]
+	userAgent := opts.ClientName
+	if endpoint.UserAgent != "" {
+		userAgent = endpoint.UserAgent
+	}
+	if userAgent != "" {
+		options = append(options, gethrpc.WithHeader("User-Agent", userAgent))
+	}
+	for name, value := range endpoint.Headers {
+		options = append(options, gethrpc.WithHeader(name, value))
+	}
+	if opts.Trace {
+		options = append(options, gethrpc.WithHTTPClient(&http.Client{
+			Transport: &tracingTransport{next: http.DefaultTransport},
+		}))
+	}
+	return options
+}
+
+// tracingTransport logs the JSON-RPC calls carried by each HTTP request
+// before handing it to next, so ethclient callers need no changes.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+// jsonrpcCall is the part of a JSON-RPC request worth logging.
+type jsonrpcCall struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			logCalls(req.URL.Host, data)
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// logCalls logs a single request or every request of a batch.
+func logCalls(host string, data []byte) {
+	data = bytes.TrimSpace(data)
+	var calls []jsonrpcCall
+	if len(data) > 0 && data[0] == '[' {
+		if json.Unmarshal(data, &calls) != nil {
+			return
+		}
+	} else {
+		var call jsonrpcCall
+		if json.Unmarshal(data, &call) != nil {
+			return
+		}
+		calls = append(calls, call)
+	}
+
+	for _, call := range calls {
+		logger.DebugKV("rpc request", "host", host, "method", call.Method, "id", string(call.ID), "batch", len(calls) > 1)
+	}
+}
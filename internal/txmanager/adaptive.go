@@ -0,0 +1,87 @@
+package txmanager
+
+import (
+	"time"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+const (
+	// DefaultAdaptiveWindow is how many broadcasts the error rate is measured
+	// over before the send rate is adjusted.
+	DefaultAdaptiveWindow = 20
+	// DefaultAdaptiveMinTPS is the slowest the adaptive throttle sends at.
+	DefaultAdaptiveMinTPS = 1
+	// DefaultAdaptiveStepTPS is how much the send rate grows after a window
+	// under the error rate threshold.
+	DefaultAdaptiveStepTPS = 5
+)
+
+// initAdaptive starts the adaptive throttle at the full rate set by WaitMilis.
+func (t *TxManager) initAdaptive() {
+	if t.AdaptiveErrorRate <= 0 || t.WaitMilis <= 0 {
+		return
+	}
+	if t.AdaptiveWindow <= 0 {
+		t.AdaptiveWindow = DefaultAdaptiveWindow
+	}
+	if t.AdaptiveMinTPS <= 0 {
+		t.AdaptiveMinTPS = DefaultAdaptiveMinTPS
+	}
+	if t.AdaptiveStepTPS <= 0 {
+		t.AdaptiveStepTPS = DefaultAdaptiveStepTPS
+	}
+	t.adaptiveMaxTPS = float64(time.Second) / float64(time.Duration(t.WaitMilis)*time.Millisecond)
+	t.adaptiveTPS = t.adaptiveMaxTPS
+	logger.Infof("Adaptive throttle: slowing down above %.0f%% RPC errors over %d broadcasts, between %.1f and %.1f tx/s",
+		t.AdaptiveErrorRate*100, t.AdaptiveWindow, min(t.AdaptiveMinTPS, t.adaptiveMaxTPS), t.adaptiveMaxTPS)
+}
+
+// adaptRate counts the outcome of a broadcast and, once a window of
+// AdaptiveWindow broadcasts is complete, halves the send rate when their
+// error rate exceeds AdaptiveErrorRate or raises it by AdaptiveStepTPS when it
+// does not. Callers must hold Mu.
+func (t *TxManager) adaptRate(failed bool) {
+	if t.adaptiveTPS == 0 {
+		return
+	}
+	t.adaptiveSent++
+	if failed {
+		t.adaptiveFailed++
+	}
+	if t.adaptiveSent < t.AdaptiveWindow {
+		return
+	}
+
+	errorRate := float64(t.adaptiveFailed) / float64(t.adaptiveSent)
+	t.adaptiveSent, t.adaptiveFailed = 0, 0
+	previous := t.adaptiveTPS
+	if errorRate > t.AdaptiveErrorRate {
+		t.adaptiveTPS = max(t.adaptiveTPS/2, min(t.AdaptiveMinTPS, t.adaptiveMaxTPS))
+	} else {
+		t.adaptiveTPS = min(t.adaptiveTPS+t.AdaptiveStepTPS, t.adaptiveMaxTPS)
+	}
+	if t.adaptiveTPS == previous {
+		return
+	}
+
+	t.RateAdjustments++
+	if t.adaptiveTPS < previous {
+		logger.Warnf("RPC error rate %.0f%% over the last %d broadcasts: slowing down from %.1f to %.1f tx/s",
+			errorRate*100, t.AdaptiveWindow, previous, t.adaptiveTPS)
+	} else {
+		logger.Infof("RPC error rate %.0f%% over the last %d broadcasts: speeding up from %.1f to %.1f tx/s",
+			errorRate*100, t.AdaptiveWindow, previous, t.adaptiveTPS)
+	}
+}
+
+// dispatchDelay is the pause before the next broadcast is launched: WaitMilis,
+// or longer while the adaptive throttle has slowed the run down.
+func (t *TxManager) dispatchDelay() time.Duration {
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+	if t.adaptiveTPS > 0 {
+		return time.Duration(float64(time.Second) / t.adaptiveTPS)
+	}
+	return time.Duration(t.WaitMilis) * time.Millisecond
+}
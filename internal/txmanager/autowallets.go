@@ -0,0 +1,89 @@
+package txmanager
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// DefaultAutoWalletsScan is how many wallet indexes AutoWallets derives at
+// most while looking for funds.
+const DefaultAutoWalletsScan = 1000
+
+// discoverWallets derives the wallets of Mnemonic one at a time, up to
+// AutoWalletsScan of them, keeping those that can afford at least one
+// transaction priced with opts, until their balances cover TxNumber
+// transactions. It returns the funded wallets with how many transactions
+// each sends: as many as it can afford, in index order, until TxNumber.
+// Empty wallets are skipped, so the funded ones are renumbered from 0.
+func (t *TxManager) discoverWallets(ctx context.Context, client rpc.EthBackend, opts ethwallet.BatchOptions) ([]*ethwallet.WalletInfo, []int, error) {
+	scan := t.AutoWalletsScan
+	if scan <= 0 {
+		scan = DefaultAutoWalletsScan
+	}
+	if t.ValueDist != nil {
+		// The actual values vary per transaction; the mean is the best guess.
+		opts.Value = t.ValueDist.Mean()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, errs := ethwallet.DeriveWalletsChan(ctx, t.Mnemonic, t.Derivation, scan, client, t.WaitMilis)
+
+	var (
+		wallets []*ethwallet.WalletInfo
+		counts  []int
+		cost    *big.Int
+		covered int
+		scanned int
+	)
+	for wallet := range stream {
+		scanned++
+		if cost == nil {
+			fees, err := wallet.FetchFeeData(ctx, opts)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to fetch fee data for wallet discovery: %w", err)
+			}
+			cost = fees.TxCost(opts.TxValue())
+		}
+
+		balance, err := ethwallet.GetBalanceAtBlock(client, wallet.Address, nil)
+		if err != nil {
+			logger.Warnf("skipping wallet %s: %v", wallet.Address, err)
+			continue
+		}
+		affordable := new(big.Int).Div(balance, cost)
+		if affordable.Sign() == 0 {
+			continue
+		}
+
+		count := t.TxNumber - covered
+		if affordable.IsInt64() && affordable.Int64() < int64(count) {
+			count = int(affordable.Int64())
+		}
+		wallets = append(wallets, wallet)
+		counts = append(counts, count)
+		covered += count
+		if covered >= t.TxNumber {
+			break
+		}
+	}
+	cancel()
+	if err := <-errs; err != nil {
+		return nil, nil, err
+	}
+
+	if len(wallets) == 0 {
+		return nil, nil, fmt.Errorf("%w: none of the first %d wallets can afford a transaction costing %s wei", ErrNoWallets, scanned, cost)
+	}
+	if covered < t.TxNumber {
+		logger.Warnf("Auto wallets: %d funded wallets among %d scanned only cover %d of %d transactions", len(wallets), scanned, covered, t.TxNumber)
+	} else {
+		logger.Infof("Auto wallets: %d funded wallets among %d scanned cover %d transactions", len(wallets), scanned, covered)
+	}
+	return wallets, counts, nil
+}
@@ -0,0 +1,81 @@
+package txmanager
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// DefaultBalanceConcurrency bounds the balance requests in flight at once.
+const DefaultBalanceConcurrency = 8
+
+// balanceResult is the outcome of one wallet's balance request.
+type balanceResult struct {
+	balance *big.Int
+	err     error
+}
+
+// fetchBalances fetches the balance of every wallet at BalanceBlock over the shared
+// client, with at most BalanceConcurrency requests in flight. Results are keyed by
+// wallet index.
+func (t *TxManager) fetchBalances(client rpc.EthBackend, wallets []*ethwallet.WalletInfo) map[int]balanceResult {
+	var (
+		mu      sync.Mutex
+		results = make(map[int]balanceResult, len(wallets))
+	)
+	forEachWallet(len(wallets), t.BalanceConcurrency, func(i int) {
+		balance, err := ethwallet.GetBalanceAtBlock(client, wallets[i].Address, t.BalanceBlock)
+		mu.Lock()
+		results[i] = balanceResult{balance: balance, err: err}
+		mu.Unlock()
+	})
+	return results
+}
+
+// forEachWallet calls fn for every wallet index in [0, n) from at most
+// concurrency workers (DefaultBalanceConcurrency when it is not positive) and
+// returns once every call has. Calls for different indexes run concurrently,
+// so fn may write only to its own index of shared slices.
+func forEachWallet(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = DefaultBalanceConcurrency
+	}
+	workers := min(concurrency, n)
+
+	var (
+		wg      sync.WaitGroup
+		indexes = make(chan int)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// logBalances prints every wallet's balance in wallet index order.
+func (t *TxManager) logBalances(client rpc.EthBackend, wallets []*ethwallet.WalletInfo) {
+	results := t.fetchBalances(client, wallets)
+
+	for i, wallet := range wallets {
+		result := results[i]
+		if result.err != nil {
+			logger.Errorf("failed to get balance for %s: %v", wallet.Name(), result.err)
+			continue
+		}
+		fmt.Println(wallet.Name(), ethwallet.FormatBalance(result.balance, t.BalanceUnit))
+	}
+}
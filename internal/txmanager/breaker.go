@@ -0,0 +1,32 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrCircuitOpen is the cause the run is aborted with once
+// ConsecutiveFailureLimit broadcasts in a row have failed.
+var ErrCircuitOpen = errors.New("circuit breaker tripped")
+
+// countFailureStreak tracks consecutive failed broadcasts: a success resets
+// the streak, and the failure that brings it to ConsecutiveFailureLimit trips
+// the breaker, aborting the run. Callers must hold Mu.
+func (t *TxManager) countFailureStreak(failed bool, p pendingTx, err error, abort context.CancelCauseFunc) {
+	if t.ConsecutiveFailureLimit <= 0 {
+		return
+	}
+	if !failed {
+		t.consecutiveFailures = 0
+		return
+	}
+	t.consecutiveFailures++
+	if t.consecutiveFailures < t.ConsecutiveFailureLimit || t.breakerErr != nil {
+		return
+	}
+	t.breakerErr = fmt.Errorf("%w after %d consecutive failed broadcasts, the last of %s (nonce %d) from %s: %w",
+		ErrCircuitOpen, t.consecutiveFailures, p.tx.Hash(), p.tx.Nonce(), p.wallet.Name(), err)
+	p.log.Errorf("%v", t.breakerErr)
+	abort(t.breakerErr)
+}
@@ -0,0 +1,315 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// broadcast sends one transaction, records the outcome and, when enabled, waits
+// for its confirmation under confirmCtx. With FailFast the first failure aborts
+// the run, with ConsecutiveFailureLimit a long enough streak of failures.
+func (t *TxManager) broadcast(ctx, confirmCtx context.Context, abort context.CancelCauseFunc, client rpc.EthBackend, p pendingTx) {
+	tx := p.tx
+	defer p.span.End()
+	ctx = trace.ContextWithSpan(ctx, p.span)
+
+	if t.wasSent(tx.Hash()) {
+		p.log.Warnf("transaction %s from %s was already sent, not sending it again", tx.Hash(), p.wallet.Name())
+		p.span.AddEvent("skipped: already sent")
+		return
+	}
+	if err := t.waitWalletTurn(ctx, p.index); err != nil {
+		t.Mu.Lock()
+		t.Cancelled++
+		t.Mu.Unlock()
+		p.log.Debugf("broadcast of %s cancelled while rate limited: %v", tx.Hash(), context.Cause(ctx))
+		return
+	}
+	sendCtx, span := tracer.Start(ctx, "broadcast")
+	t.Metrics.Count("sent", 1)
+	sendStart := t.clock().Now()
+	var sentHead *big.Int
+	if t.heads != nil {
+		sentHead = t.heads.Head()
+	}
+	err := ethwallet.ClassifySendError(t.sendWithRetry(sendCtx, p))
+	if errors.Is(err, ethwallet.ErrNonceTooLow) && t.NonceRetries > 0 {
+		p, err = t.resyncNonce(sendCtx, p, err)
+		tx = p.tx
+	}
+	p.sentHead = sentHead
+	latency := t.clock().Now().Sub(sendStart)
+	t.Metrics.Timing("broadcast_latency", latency)
+	endSpan(span, err)
+
+	t.Mu.Lock()
+	t.recordLatency(latency)
+	if err != nil && ctx.Err() != nil && errors.Is(err, context.Canceled) {
+		// The run ended underneath the broadcast; that is not the node's answer.
+		t.Cancelled++
+		p.log.Debugf("broadcast of %s cancelled: %v", tx.Hash(), context.Cause(ctx))
+	} else if err != nil && t.ExpectDrain && errors.Is(err, ethwallet.ErrInsufficientFunds) {
+		t.Drained++
+		p.log.Debugf("wallet %s drained, transaction %s not sent: %v", p.wallet.Name(), tx.Hash(), err)
+	} else if err != nil {
+		t.Failed++
+		t.Metrics.Count("failed", 1)
+		t.adaptRate(true)
+		t.countType(p.kind, true)
+		t.captureError(p, err)
+		p.log.Errorf("%d/%d failed to send transaction: %v", t.Failed, t.Failed+t.Success, err)
+		if t.FailFast && t.failFastErr == nil {
+			t.failFastErr = fmt.Errorf("transaction %s (nonce %d) from %s: %w", tx.Hash(), tx.Nonce(), p.wallet.Name(), err)
+			p.log.Errorf("==================== FAIL-FAST ====================")
+			p.log.Errorf("first broadcast error: %v", t.failFastErr)
+			p.log.Errorf("===================================================")
+			abort(t.failFastErr)
+		}
+		t.countFailureStreak(true, p, err, abort)
+	} else {
+		t.Success++
+		t.Metrics.Count("success", 1)
+		t.adaptRate(false)
+		t.countType(p.kind, false)
+		t.countFailureStreak(false, p, nil, abort)
+		t.markSent(tx.Hash())
+		t.recordProgress(p.index, tx.Nonce())
+		t.recordSpend(tx)
+		p.log.Debugf("%d/%d Transaction sent successfully: %v", t.Success, t.Failed+t.Success, tx.Hash())
+		if t.ExplorerURL != "" {
+			p.log.Infof("sent %s from %s: %s", tx.Hash(), p.wallet.Name(), t.explorerLink(tx.Hash()))
+		}
+	}
+	t.Mu.Unlock()
+	if t.OnBroadcast != nil {
+		t.OnBroadcast(tx.Hash(), err)
+	}
+	t.emit(ctx, Event{Kind: EventBroadcast, Wallet: p.index, Name: p.wallet.Name(), Hash: tx.Hash(), Err: err})
+
+	if err == nil && t.VerifyMempool {
+		t.verifyInMempool(ctx, client, p)
+	}
+	if err == nil && (t.WaitReceipts || t.EscalateAfter > 0) {
+		t.confirm(trace.ContextWithSpan(confirmCtx, p.span), client, p)
+	}
+}
+
+// sendWithRetry sends p.tx, retrying up to Retries times, RetryDelay apart,
+// while the error is retryable and the run is still going.
+func (t *TxManager) sendWithRetry(ctx context.Context, p pendingTx) error {
+	tx := p.tx
+	err := t.send(ctx, p.log, tx)
+	for attempt := 1; attempt <= t.Retries && err != nil && t.retryable(err); attempt++ {
+		p.log.Debugf("retrying %s (%d/%d) after: %v", tx.Hash(), attempt, t.Retries, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-t.clock().After(t.retryDelay()):
+		}
+
+		t.Mu.Lock()
+		t.Retried++
+		t.Mu.Unlock()
+		err = t.send(ctx, p.log, tx)
+		if errors.Is(ethwallet.ClassifySendError(err), ethwallet.ErrAlreadyKnown) {
+			// An earlier attempt reached the node after all.
+			p.log.Debugf("retry of %s: node already holds it, counting it as sent", tx.Hash())
+			return nil
+		}
+	}
+	return err
+}
+
+// wasSent reports whether a transaction with this hash was already accepted.
+func (t *TxManager) wasSent(hash common.Hash) bool {
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+	_, ok := t.sent[hash]
+	return ok
+}
+
+// markSent records an accepted transaction hash. Callers must hold Mu.
+func (t *TxManager) markSent(hash common.Hash) {
+	if t.sent == nil {
+		t.sent = make(map[common.Hash]struct{})
+	}
+	t.sent[hash] = struct{}{}
+}
+
+// retryable classifies err with Retryable, or the default patterns when it is nil.
+func (t *TxManager) retryable(err error) bool {
+	if t.Retryable != nil {
+		return t.Retryable(err)
+	}
+	return defaultRetryClassifier.Retryable(err)
+}
+
+var defaultRetryClassifier, _ = ethwallet.NewRetryClassifier(ethwallet.DefaultRetryablePatterns)
+
+// send broadcasts tx to the next endpoint of the pool, or to all of them
+// when BroadcastToAll is set.
+func (t *TxManager) send(ctx context.Context, log *logger.Logger, tx *types.Transaction) error {
+	if t.BroadcastToAll && t.pool.Len() > 1 {
+		return t.sendToAll(ctx, log, tx)
+	}
+
+	endpoint := t.pool.Next()
+	err := endpoint.Client.SendTransaction(ctx, tx)
+	if err == nil {
+		t.recordAcceptance(endpoint.URL)
+	}
+	return err
+}
+
+// endpointResult is one endpoint's answer to a broadcast.
+type endpointResult struct {
+	endpoint *rpc.Endpoint
+	err      error
+	latency  time.Duration
+}
+
+// sendToAll broadcasts tx to every endpoint concurrently and logs which ones
+// accepted it and which answered first. It succeeds if any endpoint accepts tx,
+// otherwise it returns the first rejection.
+func (t *TxManager) sendToAll(ctx context.Context, log *logger.Logger, tx *types.Transaction) error {
+	endpoints := t.pool.Endpoints()
+	results := make(chan endpointResult, len(endpoints))
+	start := t.clock().Now()
+
+	for _, endpoint := range endpoints {
+		go func() {
+			err := endpoint.Client.SendTransaction(ctx, tx)
+			results <- endpointResult{endpoint: endpoint, err: err, latency: t.clock().Now().Sub(start)}
+		}()
+	}
+
+	var (
+		firstAccepted string
+		firstErr      error
+		accepted      int
+	)
+	for range endpoints {
+		r := <-results
+		if r.err != nil {
+			log.Debugf("%s rejected by %s after %v: %v", tx.Hash(), r.endpoint.URL, r.latency, r.err)
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			continue
+		}
+
+		accepted++
+		t.recordAcceptance(r.endpoint.URL)
+		if firstAccepted == "" {
+			firstAccepted = r.endpoint.URL
+			t.recordFirstAcceptance(r.endpoint.URL)
+		}
+		log.Debugf("%s accepted by %s after %v", tx.Hash(), r.endpoint.URL, r.latency)
+	}
+
+	if accepted == 0 {
+		return firstErr
+	}
+	log.Debugf("%s accepted by %d/%d endpoints, first by %s", tx.Hash(), accepted, len(endpoints), firstAccepted)
+	return nil
+}
+
+// recordAcceptance counts a transaction accepted by the endpoint at url.
+func (t *TxManager) recordAcceptance(url string) {
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+	if t.endpointAccepted == nil {
+		t.endpointAccepted = make(map[string]int)
+	}
+	t.endpointAccepted[url]++
+}
+
+// recordFirstAcceptance counts a transaction the endpoint at url accepted before any other.
+func (t *TxManager) recordFirstAcceptance(url string) {
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+	if t.endpointFirst == nil {
+		t.endpointFirst = make(map[string]int)
+	}
+	t.endpointFirst[url]++
+}
+
+// logEndpointSummary prints how many transactions each endpoint accepted.
+func (t *TxManager) logEndpointSummary() {
+	if t.pool == nil || t.pool.Len() < 2 {
+		return
+	}
+
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+	shares := t.endpointShares()
+	for _, endpoint := range t.pool.Endpoints() {
+		if t.BroadcastToAll {
+			logger.Infof("Endpoint %s accepted %d transactions (%d first)", endpoint.URL, t.endpointAccepted[endpoint.URL], t.endpointFirst[endpoint.URL])
+		} else if share, ok := shares[endpoint.URL]; ok {
+			logger.Infof("Endpoint %s accepted %d transactions (%.1f%%, weighted %.1f%%)", endpoint.URL, share.Accepted, share.Actual, share.Target)
+		} else {
+			logger.Infof("Endpoint %s accepted %d transactions", endpoint.URL, t.endpointAccepted[endpoint.URL])
+		}
+	}
+}
+
+// endpointStream is the RNG stream of weighted endpoint selection.
+const endpointStream = payloadStream - 3
+
+// weighEndpoints applies RpcWeights to the pool, if set.
+func (t *TxManager) weighEndpoints() error {
+	if t.RpcWeights == nil {
+		return nil
+	}
+	t.resolveSeed()
+	return t.pool.SetWeights(t.RpcWeights, rand.New(rand.NewPCG(t.Seed, endpointStream)))
+}
+
+// EndpointShare compares the percentage of broadcasts RpcWeights meant an
+// endpoint to take with the percentage of accepted transactions it took.
+type EndpointShare struct {
+	Target   float64 `json:"target_pct"`
+	Accepted int     `json:"accepted"`
+	Actual   float64 `json:"actual_pct"`
+}
+
+// endpointShares returns the share of every endpoint by URL, nil unless
+// RpcWeights is set. Callers must hold Mu.
+func (t *TxManager) endpointShares() map[string]EndpointShare {
+	if t.RpcWeights == nil || t.pool == nil {
+		return nil
+	}
+	var weights float64
+	for _, w := range t.RpcWeights {
+		weights += w
+	}
+	var accepted int
+	for _, n := range t.endpointAccepted {
+		accepted += n
+	}
+
+	shares := make(map[string]EndpointShare, t.pool.Len())
+	for i, endpoint := range t.pool.Endpoints() {
+		share := EndpointShare{
+			Target:   100 * t.RpcWeights[i] / weights,
+			Accepted: t.endpointAccepted[endpoint.URL],
+		}
+		if accepted > 0 {
+			share.Actual = 100 * float64(share.Accepted) / float64(accepted)
+		}
+		shares[endpoint.URL] = share
+	}
+	return shares
+}
@@ -0,0 +1,79 @@
+package txmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// benchmarkTxs is how many transactions every iteration of BenchmarkBroadcast sends.
+const benchmarkTxs = 256
+
+// BenchmarkBroadcast compares sending a batch one transaction at a time with
+// sending it from a number of goroutines, and with broadcastTransactions, which
+// starts one per transaction. The backend takes a fixed time per broadcast,
+// like a node a round trip away, so the gain from concurrency shows; tx/s is
+// the throughput.
+func BenchmarkBroadcast(b *testing.B) {
+	for _, delay := range []time.Duration{0, 200 * time.Microsecond} {
+		client := &slowBackend{NullBackend: rpc.NewNullBackend(), delay: delay}
+		pool := poolOf(client)
+		txs := signedTxs(b, testWallet(b, client), benchmarkTxs)
+
+		b.Run(fmt.Sprintf("delay=%v/sequential", delay), func(b *testing.B) {
+			benchmarkBroadcast(b, pool, txs, func(m *TxManager, r *runState) {
+				for _, p := range txs {
+					m.broadcast(r.ctx, r.confirmCtx, r.abort, r.client, p)
+				}
+			})
+		})
+		for _, workers := range []int{4, 16, 64} {
+			b.Run(fmt.Sprintf("delay=%v/workers=%d", delay, workers), func(b *testing.B) {
+				benchmarkBroadcast(b, pool, txs, func(m *TxManager, r *runState) {
+					queue := make(chan pendingTx)
+					var wg sync.WaitGroup
+					for range workers {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+							for p := range queue {
+								m.broadcast(r.ctx, r.confirmCtx, r.abort, r.client, p)
+							}
+						}()
+					}
+					for _, p := range txs {
+						queue <- p
+					}
+					close(queue)
+					wg.Wait()
+				})
+			})
+		}
+		b.Run(fmt.Sprintf("delay=%v/parallel", delay), func(b *testing.B) {
+			benchmarkBroadcast(b, pool, txs, func(m *TxManager, r *runState) {
+				m.broadcastTransactions(r, txs)
+			})
+		})
+	}
+}
+
+// benchmarkBroadcast times send over txs, with a fresh manager every
+// iteration so no transaction is skipped as already sent.
+func benchmarkBroadcast(b *testing.B, pool *rpc.RpcPool, txs []pendingTx, send func(*TxManager, *runState)) {
+	b.ReportAllocs()
+	start := time.Now()
+	for range b.N {
+		m := &TxManager{Mu: &sync.Mutex{}, pool: pool}
+		ctx, abort := context.WithCancelCause(context.Background())
+		send(m, &runState{ctx: ctx, abort: abort, confirmCtx: ctx, client: pool.Primary().Client})
+		abort(nil)
+		if m.Success != len(txs) {
+			b.Fatalf("%d of %d transactions accepted", m.Success, len(txs))
+		}
+	}
+	b.ReportMetric(float64(b.N*len(txs))/time.Since(start).Seconds(), "tx/s")
+}
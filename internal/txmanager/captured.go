@@ -0,0 +1,46 @@
+package txmanager
+
+import (
+	"time"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// ErrorRecord is the full context of one failed broadcast, kept verbatim for
+// the first CaptureErrors failures.
+type ErrorRecord struct {
+	Time        time.Time `json:"time"`
+	WalletIndex int       `json:"wallet_index"`
+	Wallet      string    `json:"wallet"`
+	Nonce       uint64    `json:"nonce"`
+	TxHash      string    `json:"tx_hash"`
+	Error       string    `json:"error"`
+}
+
+// captureError keeps the details of a failed broadcast while fewer than
+// CaptureErrors have been kept. Callers must hold Mu.
+func (t *TxManager) captureError(p pendingTx, err error) {
+	if len(t.capturedErrors) >= t.CaptureErrors {
+		return
+	}
+	t.capturedErrors = append(t.capturedErrors, ErrorRecord{
+		Time:        t.clock().Now(),
+		WalletIndex: p.index,
+		Wallet:      p.wallet.Name(),
+		Nonce:       p.tx.Nonce(),
+		TxHash:      p.tx.Hash().Hex(),
+		Error:       err.Error(),
+	})
+}
+
+// logCapturedErrors prints the captured failures in the order they happened.
+func logCapturedErrors(records []ErrorRecord) {
+	if len(records) == 0 {
+		return
+	}
+	logger.Infof("First %d failed broadcasts:", len(records))
+	for i, r := range records {
+		logger.Infof("  %d. %s wallet %d (%s) nonce %d tx %s: %s",
+			i+1, r.Time.Format(time.RFC3339Nano), r.WalletIndex, r.Wallet, r.Nonce, r.TxHash, r.Error)
+	}
+}
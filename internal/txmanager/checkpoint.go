@@ -0,0 +1,171 @@
+package txmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// CheckpointVersion identifies the layout of Checkpoint, like ReportVersion.
+const CheckpointVersion = 1
+
+// Checkpoint is the progress of a run, saved periodically so an interrupted
+// run can be resumed without resending what the node already accepted.
+type Checkpoint struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Wallets   int       `json:"wallets"`
+	TxNumber  int       `json:"txns"`
+
+	// Sent is the number of transactions each wallet got accepted, by wallet index.
+	Sent map[int]int `json:"sent"`
+	// Nonces is the highest nonce each wallet got accepted, by wallet index.
+	Nonces map[int]uint64 `json:"nonces"`
+	// Hashes lists every accepted transaction.
+	Hashes []common.Hash `json:"hashes"`
+}
+
+// LoadCheckpoint reads a checkpoint written by a previous run.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if cp.Version != CheckpointVersion {
+		return nil, fmt.Errorf("checkpoint %s has version %d, want %d", path, cp.Version, CheckpointVersion)
+	}
+	return &cp, nil
+}
+
+// resume applies a checkpoint to the per-wallet transaction counts: wallets
+// only build what they have not sent yet, and the hashes already accepted are
+// never broadcast again.
+func (t *TxManager) resume(cp *Checkpoint, counts []int) error {
+	if cp.Wallets != t.WalletsNumber {
+		return fmt.Errorf("checkpoint was written for %d wallets, this run has %d", cp.Wallets, t.WalletsNumber)
+	}
+
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+
+	skipped := 0
+	for i := range counts {
+		done := min(cp.Sent[i], counts[i])
+		counts[i] -= done
+		skipped += done
+	}
+	t.initProgress()
+	for i, n := range cp.Sent {
+		t.walletSent[i] = n
+	}
+	for i, n := range cp.Nonces {
+		t.walletNonce[i] = n
+	}
+	for _, hash := range cp.Hashes {
+		t.markSent(hash)
+	}
+
+	logger.Infof("Resuming from checkpoint of %s: %d transactions already sent", cp.UpdatedAt.Format(time.RFC3339), skipped)
+	return nil
+}
+
+// recordProgress notes that wallet index got the transaction with this nonce
+// accepted. Callers must hold Mu.
+func (t *TxManager) recordProgress(index int, nonce uint64) {
+	t.initProgress()
+	t.walletSent[index]++
+	if nonce > t.walletNonce[index] {
+		t.walletNonce[index] = nonce
+	}
+}
+
+// initProgress allocates the per-wallet progress maps. Callers must hold Mu.
+func (t *TxManager) initProgress() {
+	if t.walletSent == nil {
+		t.walletSent = make(map[int]int)
+		t.walletNonce = make(map[int]uint64)
+	}
+}
+
+// checkpoint snapshots the progress of the run.
+func (t *TxManager) checkpoint() *Checkpoint {
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+
+	cp := &Checkpoint{
+		Version:   CheckpointVersion,
+		UpdatedAt: t.clock().Now(),
+		Wallets:   t.WalletsNumber,
+		TxNumber:  t.TxNumber,
+		Sent:      make(map[int]int, len(t.walletSent)),
+		Nonces:    make(map[int]uint64, len(t.walletNonce)),
+		Hashes:    make([]common.Hash, 0, len(t.sent)),
+	}
+	for i, n := range t.walletSent {
+		cp.Sent[i] = n
+	}
+	for i, n := range t.walletNonce {
+		cp.Nonces[i] = n
+	}
+	for hash := range t.sent {
+		cp.Hashes = append(cp.Hashes, hash)
+	}
+	return cp
+}
+
+// writeCheckpoint saves the progress to CheckpointPath atomically: it writes a
+// temporary file next to it and renames it over the old one, so a crash never
+// leaves a truncated checkpoint behind.
+func (t *TxManager) writeCheckpoint() error {
+	data, err := json.MarshalIndent(t.checkpoint(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(t.CheckpointPath), filepath.Base(t.CheckpointPath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), t.CheckpointPath); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// checkpointLoop writes a checkpoint every CheckpointInterval until ctx ends.
+func (t *TxManager) checkpointLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.clock().After(t.CheckpointInterval):
+		}
+		if err := t.writeCheckpoint(); err != nil {
+			logger.Warnf("%v", err)
+		}
+	}
+}
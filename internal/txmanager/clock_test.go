@@ -0,0 +1,94 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManualClockAfter(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	clock := NewManualClock(start)
+
+	early := clock.After(time.Second)
+	late := clock.After(3 * time.Second)
+	select {
+	case <-clock.After(0):
+	default:
+		t.Fatal("After(0) did not fire at once")
+	}
+
+	clock.Advance(2 * time.Second)
+	select {
+	case now := <-early:
+		if want := start.Add(2 * time.Second); !now.Equal(want) {
+			t.Errorf("After(1s) fired at %v, want %v", now, want)
+		}
+	default:
+		t.Fatal("After(1s) did not fire after advancing 2s")
+	}
+	select {
+	case <-late:
+		t.Fatal("After(3s) fired after advancing 2s")
+	default:
+	}
+	if n := clock.Waiters(); n != 1 {
+		t.Errorf("Waiters() = %d, want 1", n)
+	}
+
+	clock.Advance(time.Second)
+	<-late
+	if got, want := clock.Now(), start.Add(3*time.Second); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+}
+
+func TestManualClockSleep(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		clock.Sleep(time.Minute)
+		close(done)
+	}()
+
+	waitForWaiters(t, clock, 1)
+	clock.Advance(59 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("Sleep(1m) returned after 59s")
+	default:
+	}
+	clock.Advance(time.Second)
+	<-done
+}
+
+func TestWithTimeoutFollowsClock(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	m := &TxManager{Clock: clock}
+
+	ctx, cancel := m.withTimeout(context.Background(), time.Hour)
+	defer cancel()
+	waitForWaiters(t, clock, 1)
+	if ctx.Err() != nil {
+		t.Fatal("context ended before the clock moved")
+	}
+
+	clock.Advance(time.Hour)
+	<-ctx.Done()
+	if cause := context.Cause(ctx); !errors.Is(cause, context.DeadlineExceeded) {
+		t.Errorf("context.Cause = %v, want %v", cause, context.DeadlineExceeded)
+	}
+}
+
+// waitForWaiters waits until n goroutines are blocked on clock.
+func waitForWaiters(t *testing.T, clock *ManualClock, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for clock.Waiters() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines waiting on the clock, want %d", clock.Waiters(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
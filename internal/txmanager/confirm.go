@@ -0,0 +1,192 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultReceiptPollInterval is frequent enough to notice a receipt within
+	// a block on chains with ~1s blocks without flooding the RPC.
+	DefaultReceiptPollInterval = time.Second
+	// DefaultReceiptTimeout leaves room for ~10 mainnet blocks before a
+	// transaction is declared unconfirmed.
+	DefaultReceiptTimeout = 2 * time.Minute
+	// DefaultEscalatePercent is the smallest fee bump geth accepts for a replacement.
+	DefaultEscalatePercent = 10
+)
+
+// waitForReceipt polls the node every ReceiptPollInterval until one of hashes is
+// mined or ctx ends. Several hashes are watched when a transaction has been
+// replaced by fee escalation, since any of the versions may be the one mined.
+func (t *TxManager) waitForReceipt(ctx context.Context, client rpc.EthBackend, log *logger.Logger, hashes []common.Hash) (*types.Receipt, error) {
+	interval := t.ReceiptPollInterval
+	if interval <= 0 {
+		interval = DefaultReceiptPollInterval
+	}
+
+	for {
+		for _, hash := range hashes {
+			receipt, err := client.TransactionReceipt(ctx, hash)
+			if err == nil {
+				return receipt, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) && ctx.Err() == nil {
+				log.Debugf("failed to fetch receipt for %s, retrying: %v", hash, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-t.clock().After(interval):
+		}
+	}
+}
+
+// confirm waits, for at most ReceiptTimeout and ConfirmBlockTimeout blocks,
+// for the receipt of a broadcast transaction and records the outcome. When EscalateAfter is set, every time
+// that much passes without a receipt the transaction is re-signed at the same
+// nonce with fees raised by EscalatePercent and broadcast again.
+func (t *TxManager) confirm(ctx context.Context, client rpc.EthBackend, p pendingTx) {
+	ctx, span := tracer.Start(ctx, "confirm")
+	defer span.End()
+
+	if t.ReceiptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = t.withTimeout(ctx, t.ReceiptTimeout)
+		defer cancel()
+	}
+	if t.ConfirmBlockTimeout > 0 && t.heads != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = t.heads.withBlockTimeout(ctx, p.sentHead, t.ConfirmBlockTimeout)
+		defer cancel()
+	}
+
+	current := p.tx
+	hashes := []common.Hash{current.Hash()}
+	escalating := t.EscalateAfter > 0
+
+	for {
+		waitCtx, cancelWait := ctx, context.CancelFunc(func() {})
+		if escalating {
+			waitCtx, cancelWait = t.withTimeout(ctx, t.EscalateAfter)
+		}
+		receipt, err := t.waitForReceipt(waitCtx, client, p.log, hashes)
+		cancelWait()
+
+		if err == nil {
+			var reason string
+			if receipt.Status == types.ReceiptStatusFailed {
+				reason = revertReason(ctx, client, p, receipt)
+			}
+			t.recordReceipt(p, receipt, reason)
+			if t.OnConfirmed != nil {
+				t.OnConfirmed(receipt)
+			}
+			span.SetAttributes(
+				attribute.Int64("receipt.block", receipt.BlockNumber.Int64()),
+				attribute.Int64("receipt.status", int64(receipt.Status)),
+			)
+			return
+		}
+
+		if ctx.Err() != nil {
+			t.Mu.Lock()
+			t.Unconfirmed++
+			t.Mu.Unlock()
+			p.log.Warnf("transaction %s not confirmed: %v", p.tx.Hash(), context.Cause(ctx))
+			span.SetStatus(codes.Error, "not confirmed")
+			return
+		}
+
+		replacement, err := t.escalate(ctx, p, current)
+		if errors.Is(err, errSpendCap) {
+			// Later bumps only cost more; wait for the current version instead.
+			p.log.Warnf("not escalating fees of %s further: %v", current.Hash(), err)
+			escalating = false
+			continue
+		}
+		if err != nil {
+			p.log.Warnf("failed to escalate fees of %s: %v", current.Hash(), err)
+			continue
+		}
+
+		t.Mu.Lock()
+		if len(hashes) == 1 {
+			t.Escalated++
+		}
+		t.Escalations++
+		t.Mu.Unlock()
+
+		span.AddEvent("escalated", trace.WithAttributes(attribute.String("tx.hash", replacement.Hash().Hex())))
+		current = replacement
+		hashes = append(hashes, replacement.Hash())
+	}
+}
+
+// escalate replaces tx with a copy paying EscalatePercent more and broadcasts it.
+// The fee it adds is counted against MaxSpend first: errSpendCap means the
+// cap leaves no room for it.
+func (t *TxManager) escalate(ctx context.Context, p pendingTx, tx *types.Transaction) (*types.Transaction, error) {
+	cost := bumpCost(tx, t.EscalatePercent)
+	t.Mu.Lock()
+	fits := t.reserveCost(cost)
+	t.Mu.Unlock()
+	if !fits {
+		return nil, errSpendCap
+	}
+
+	replacement, err := p.wallet.BumpEIP1559Fees(tx, t.EscalatePercent)
+	if err == nil {
+		err = t.send(ctx, p.log, replacement)
+	}
+	t.Mu.Lock()
+	if err != nil {
+		t.releaseCost(cost)
+	} else {
+		t.addSpent(cost)
+	}
+	t.Mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	p.log.Infof("escalated %s (nonce %d) to %s: tip %s, max fee %s",
+		tx.Hash(), tx.Nonce(), replacement.Hash(), replacement.GasTipCap(), replacement.GasFeeCap())
+	return replacement, nil
+}
+
+// recordReceipt counts a mined transaction sent as p; reason is why it
+// reverted, if it did.
+func (t *TxManager) recordReceipt(p pendingTx, receipt *types.Receipt, reason string) {
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+
+	t.Confirmed++
+	t.recordGasUsage(p.tx, receipt)
+	if receipt.Status == types.ReceiptStatusFailed {
+		t.Reverted++
+		t.reverts = append(t.reverts, RevertRecord{
+			WalletIndex: p.index,
+			Wallet:      p.wallet.Name(),
+			Nonce:       p.tx.Nonce(),
+			TxHash:      receipt.TxHash.Hex(),
+			Block:       receipt.BlockNumber.Uint64(),
+			Reason:      reason,
+		})
+		p.log.Warnf("transaction %s reverted in block %s: %s", receipt.TxHash, receipt.BlockNumber, reason)
+		return
+	}
+	p.log.Debugf("transaction %s confirmed in block %s", receipt.TxHash, receipt.BlockNumber)
+}
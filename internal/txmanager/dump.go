@@ -0,0 +1,32 @@
+package txmanager
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// dumpRawTxs writes the RLP encoding of every signed transaction to path as
+// 0x-prefixed hex, one per line in dispatch order, ready for eth_sendRawTransaction.
+func dumpRawTxs(path string, txs []pendingTx) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create raw transaction dump: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, p := range txs {
+		raw, err := p.tx.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("failed to encode transaction %s: %w", p.tx.Hash(), err)
+		}
+		fmt.Fprintln(w, hexutil.Encode(raw))
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write raw transaction dump: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,118 @@
+package txmanager
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+)
+
+// CostEstimate is the worst-case spend of a run at the current fees: every
+// transaction is priced at its full max fee, so the actual cost is usually lower.
+type CostEstimate struct {
+	Transactions int
+	GasLimit     uint64
+	MaxFeePerGas *big.Int
+	// GasWei is Transactions * GasLimit * MaxFeePerGas, ValueWei the Wei
+	// transferred in total and TotalWei their sum. With a TxTemplate, GasLimit
+	// and MaxFeePerGas are those of the batch, and GasWei and ValueWei add up
+	// the template's transactions, each at its own limit, fee cap and value.
+	GasWei   *big.Int
+	ValueWei *big.Int
+	TotalWei *big.Int
+}
+
+// TotalEther returns TotalWei as a decimal Ether string.
+func (c CostEstimate) TotalEther() string {
+	return ethwallet.FormatEther(c.TotalWei)
+}
+
+// plannedTransactions returns how many transactions Run would build: with an
+// even split the remainder of TxNumber / WalletsNumber is dropped.
+func (t *TxManager) plannedTransactions() int {
+	if t.TxTemplate != nil {
+		return len(t.TxTemplate) * max(t.WalletsNumber, 0)
+	}
+	if t.WalletTxCounts != nil {
+		return sumCounts(t.WalletTxCounts)
+	}
+	if t.SplitWeights != nil || t.WalletsNumber <= 0 {
+		return t.TxNumber
+	}
+	return t.TxNumber / t.WalletsNumber * t.WalletsNumber
+}
+
+// EstimateCost projects the total spend of the run without sending anything.
+// Fees are priced from the first wallet the same way Run prices them.
+func (t *TxManager) EstimateCost() (CostEstimate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool, err := t.dialPool(ctx)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	defer pool.Close()
+
+	wallets, err := t.deriveWallets(pool.Primary().Client, 1)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+
+	to, err := t.recipient(pool.Primary().Client)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+
+	opts := ethwallet.BatchOptions{
+		TipPercentile: t.TipPercentile,
+		TipBlocks:     t.TipBlocks,
+		Value:         t.Value,
+		MaxFee:        t.MaxFee,
+		MinTip:        t.MinTip,
+		GasMultiplier: t.GasMultiplier,
+		Oracle:        t.GasOracle,
+		Signer:        t.Signer,
+		To:            to,
+		Data:          t.payload(),
+		Tag:           t.dataTag(0),
+	}
+	if t.ValueDist != nil {
+		// The actual values vary per transaction; the mean is the best guess.
+		opts.Value = t.ValueDist.Mean()
+	}
+	fees, ok := t.ReplayFees[0]
+	if !ok {
+		var err error
+		if fees, err = wallets[0].FetchFeeData(ctx, opts); err != nil {
+			return CostEstimate{}, fmt.Errorf("failed to fetch fee data: %w", err)
+		}
+	}
+
+	txs := big.NewInt(int64(t.plannedTransactions()))
+	gas := new(big.Int).Mul(txs, new(big.Int).SetUint64(fees.GasLimit))
+	gas.Mul(gas, fees.MaxFeeCap)
+	value := new(big.Int).Mul(txs, opts.TxValue())
+	if t.TxTemplate != nil {
+		// Every wallet sends the template, so price it once and scale it.
+		opts.Sequence = t.TxTemplate
+		perWallet, perWalletValue, err := wallets[0].SequenceCost(ctx, opts, fees)
+		if err != nil {
+			return CostEstimate{}, fmt.Errorf("failed to price the transaction template: %w", err)
+		}
+		walletsNumber := big.NewInt(int64(t.WalletsNumber))
+		gas = perWallet.Mul(perWallet, walletsNumber)
+		value = perWalletValue.Mul(perWalletValue, walletsNumber)
+	}
+
+	return CostEstimate{
+		Transactions: int(txs.Int64()),
+		GasLimit:     fees.GasLimit,
+		MaxFeePerGas: fees.MaxFeeCap,
+		GasWei:       gas,
+		ValueWei:     value,
+		TotalWei:     new(big.Int).Add(gas, value),
+	}, nil
+}
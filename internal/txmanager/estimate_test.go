@@ -0,0 +1,40 @@
+package txmanager
+
+import (
+	"math/big"
+	"testing"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+func TestEstimateCostTemplate(t *testing.T) {
+	m := testManager(rpc.NewNullBackend(), 3, 0)
+	m.TxTemplate = []ethwallet.TxSpec{
+		{Value: big.NewInt(5), GasLimit: 50_000},
+		{MaxFeeCap: big.NewInt(10e9)},
+	}
+
+	estimate, err := m.EstimateCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.Transactions != 6 {
+		t.Errorf("Transactions = %d, want 6", estimate.Transactions)
+	}
+
+	// Per wallet: the first at its own gas limit, the second at its own fee cap.
+	gas := new(big.Int).Mul(big.NewInt(50_000), estimate.MaxFeePerGas)
+	gas.Add(gas, new(big.Int).Mul(new(big.Int).SetUint64(estimate.GasLimit), big.NewInt(10e9)))
+	gas.Mul(gas, big.NewInt(3))
+	if estimate.GasWei.Cmp(gas) != 0 {
+		t.Errorf("GasWei = %v, want %v", estimate.GasWei, gas)
+	}
+	value := big.NewInt(3 * (5 + ethwallet.TransferValue))
+	if estimate.ValueWei.Cmp(value) != 0 {
+		t.Errorf("ValueWei = %v, want %v", estimate.ValueWei, value)
+	}
+	if total := new(big.Int).Add(gas, value); estimate.TotalWei.Cmp(total) != 0 {
+		t.Errorf("TotalWei = %v, want %v", estimate.TotalWei, total)
+	}
+}
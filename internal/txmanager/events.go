@@ -0,0 +1,60 @@
+package txmanager
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EventKind tells what an Event reports.
+type EventKind int
+
+const (
+	// EventStart comes once, before the first broadcast, with Planned set.
+	EventStart EventKind = iota
+	// EventBroadcast comes after every broadcast, with the wallet that sent
+	// it and the node's answer.
+	EventBroadcast
+	// EventDone comes once the broadcasts are over, before the summary.
+	EventDone
+)
+
+// Event is one step of a run, sent to Events.
+type Event struct {
+	Kind EventKind
+	// Planned holds how many transactions each wallet is about to
+	// broadcast, by wallet index.
+	Planned []int
+
+	Wallet int
+	Name   string
+	Hash   common.Hash
+	// Err is nil when the node accepted the transaction.
+	Err error
+}
+
+// emit sends e to Events, unless the run stops first. It must not be called
+// while holding Mu.
+func (t *TxManager) emit(ctx context.Context, e Event) {
+	if t.Events == nil {
+		return
+	}
+	select {
+	case t.Events <- e:
+	case <-ctx.Done():
+	}
+}
+
+// emitStart sends the EventStart of the transactions about to be broadcast.
+func (t *TxManager) emitStart(ctx context.Context, txs []pendingTx, wallets int) {
+	if t.Events == nil {
+		return
+	}
+	planned := make([]int, wallets)
+	for _, p := range txs {
+		if p.index < wallets {
+			planned[p.index]++
+		}
+	}
+	t.emit(ctx, Event{Kind: EventStart, Planned: planned})
+}
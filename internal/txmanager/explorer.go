@@ -0,0 +1,26 @@
+package txmanager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValidateExplorerURL checks that tmpl, an ExplorerURL template such as
+// "https://sepolia.etherscan.io/tx/%s", has exactly one %s for the hash and
+// no other verb.
+func ValidateExplorerURL(tmpl string) error {
+	if strings.Count(tmpl, "%s") != 1 {
+		return fmt.Errorf("explorer URL %q must contain %%s exactly once, for the transaction hash", tmpl)
+	}
+	if rest := strings.ReplaceAll(strings.ReplaceAll(tmpl, "%%", ""), "%s", ""); strings.Contains(rest, "%") {
+		return fmt.Errorf("explorer URL %q may only contain %%s, or %%%% for a literal %%", tmpl)
+	}
+	return nil
+}
+
+// explorerLink returns the ExplorerURL page of hash.
+func (t *TxManager) explorerLink(hash common.Hash) string {
+	return fmt.Sprintf(t.ExplorerURL, hash.Hex())
+}
@@ -0,0 +1,129 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// DefaultFaucetWait is how long the prefund phase waits for the faucet's
+// transactions to land.
+const DefaultFaucetWait = 2 * time.Minute
+
+// FaucetRecord is the outcome of the faucet request of one wallet.
+type FaucetRecord struct {
+	Wallet  string         `json:"wallet"`
+	Address common.Address `json:"address"`
+	Hash    *common.Hash   `json:"tx_hash,omitempty"`
+	Funded  bool           `json:"funded"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// prefund asks Faucet to fund every wallet, then waits, for at most
+// FaucetWait, for each funding to land: the receipt of the funding
+// transaction when the faucet names it, a higher balance otherwise. At most
+// BalanceConcurrency wallets are funded at once. Wallets the faucet does not
+// fund are only reported, as they may hold funds already.
+func (t *TxManager) prefund(ctx context.Context, client rpc.EthBackend, wallets []*ethwallet.WalletInfo) {
+	ctx, cancel := t.withTimeout(ctx, t.faucetWait())
+	defer cancel()
+
+	logger.Infof("Requesting funds for %d wallets from %s", len(wallets), t.Faucet.URL())
+	records := make([]FaucetRecord, len(wallets))
+	forEachWallet(len(wallets), t.BalanceConcurrency, func(i int) {
+		records[i] = t.fundWallet(ctx, client, wallets[i])
+	})
+
+	funded := 0
+	for _, r := range records {
+		if r.Funded {
+			funded++
+		} else {
+			logger.Warnf("  %s was not funded: %s", r.Wallet, r.Error)
+		}
+	}
+	if funded < len(records) {
+		logger.Warnf("Faucet funded %d/%d wallets", funded, len(records))
+	} else {
+		logger.Infof("Faucet funded %d/%d wallets", funded, len(records))
+	}
+
+	t.Mu.Lock()
+	t.faucetRecords = records
+	t.Mu.Unlock()
+}
+
+func (t *TxManager) faucetWait() time.Duration {
+	if t.FaucetWait <= 0 {
+		return DefaultFaucetWait
+	}
+	return t.FaucetWait
+}
+
+// fundWallet requests funds for wallet and waits for them under ctx.
+func (t *TxManager) fundWallet(ctx context.Context, client rpc.EthBackend, wallet *ethwallet.WalletInfo) FaucetRecord {
+	record := FaucetRecord{Wallet: wallet.Name(), Address: wallet.Address}
+	fail := func(err error) FaucetRecord {
+		if errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+			err = fmt.Errorf("not funded within %v", t.faucetWait())
+		}
+		record.Error = err.Error()
+		return record
+	}
+
+	before, err := client.BalanceAt(ctx, wallet.Address, nil)
+	if err != nil {
+		return fail(fmt.Errorf("failed to get balance: %w", err))
+	}
+	hash, err := t.Faucet.Fund(ctx, wallet.Address)
+	if err != nil {
+		return fail(err)
+	}
+
+	if hash != (common.Hash{}) {
+		record.Hash = &hash
+		receipt, err := t.waitForReceipt(ctx, client, nil, []common.Hash{hash})
+		if err != nil {
+			return fail(err)
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return fail(fmt.Errorf("funding transaction %s reverted", hash))
+		}
+		record.Funded = true
+		return record
+	}
+
+	if err := t.waitForBalanceAbove(ctx, client, wallet.Address, before); err != nil {
+		return fail(err)
+	}
+	record.Funded = true
+	return record
+}
+
+// waitForBalanceAbove polls the balance of address every ReceiptPollInterval
+// until it exceeds before or ctx ends.
+func (t *TxManager) waitForBalanceAbove(ctx context.Context, client rpc.EthBackend, address common.Address, before *big.Int) error {
+	interval := t.ReceiptPollInterval
+	if interval <= 0 {
+		interval = DefaultReceiptPollInterval
+	}
+	for {
+		balance, err := client.BalanceAt(ctx, address, nil)
+		if err == nil && balance.Cmp(before) > 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.clock().After(interval):
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package txmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+)
+
+// ParseFeeOverrides parses a -wallet-fee-overrides spec into fee overrides by wallet index.
+//
+// The spec is a JSON object mapping wallet indices to the fees that wallet
+// sends with, in Gwei, e.g. {"0": {"tip_gwei": "5"}, "3": {"tip_gwei": "0.5", "max_fee_gwei": "40"}}.
+// Wallets not named keep the default fees.
+func ParseFeeOverrides(spec string, wallets int) (map[int]ethwallet.FeeOverride, error) {
+	var raw map[string]struct {
+		TipGwei    string `json:"tip_gwei"`
+		MaxFeeGwei string `json:"max_fee_gwei"`
+	}
+	if err := json.Unmarshal([]byte(spec), &raw); err != nil {
+		return nil, fmt.Errorf("invalid wallet fee overrides %q: %w", spec, err)
+	}
+
+	overrides := make(map[int]ethwallet.FeeOverride, len(raw))
+	for key, fees := range raw {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= wallets {
+			return nil, fmt.Errorf("wallet fee overrides: %q is not a wallet index between 0 and %d", key, wallets-1)
+		}
+		if fees.TipGwei == "" && fees.MaxFeeGwei == "" {
+			return nil, fmt.Errorf("wallet fee overrides: wallet %d sets neither tip_gwei nor max_fee_gwei", index)
+		}
+
+		var override ethwallet.FeeOverride
+		if fees.TipGwei != "" {
+			if override.TipCap, err = ethwallet.GweiToWei(fees.TipGwei); err != nil {
+				return nil, fmt.Errorf("wallet fee overrides: wallet %d: %w", index, err)
+			}
+		}
+		if fees.MaxFeeGwei != "" {
+			if override.MaxFeeCap, err = ethwallet.GweiToWei(fees.MaxFeeGwei); err != nil {
+				return nil, fmt.Errorf("wallet fee overrides: wallet %d: %w", index, err)
+			}
+		}
+		if override.TipCap != nil && override.MaxFeeCap != nil && override.TipCap.Cmp(override.MaxFeeCap) > 0 {
+			return nil, fmt.Errorf("wallet fee overrides: wallet %d: tip exceeds max fee", index)
+		}
+		overrides[index] = override
+	}
+	return overrides, nil
+}
+
+// ParseWalletLabels parses a -wallet-labels spec: a JSON object mapping wallet
+// indices to the label shown for that wallet in logs and reports instead of
+// its address, e.g. {"0": "sponsor", "1": "relayer"}.
+func ParseWalletLabels(spec string, wallets int) (map[int]string, error) {
+	var raw map[string]string
+	if err := json.Unmarshal([]byte(spec), &raw); err != nil {
+		return nil, fmt.Errorf("invalid wallet labels %q: %w", spec, err)
+	}
+
+	labels := make(map[int]string, len(raw))
+	for key, label := range raw {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= wallets {
+			return nil, fmt.Errorf("wallet labels: %q is not a wallet index between 0 and %d", key, wallets-1)
+		}
+		if label == "" {
+			return nil, fmt.Errorf("wallet labels: wallet %d has an empty label", index)
+		}
+		labels[index] = label
+	}
+	return labels, nil
+}
@@ -0,0 +1,88 @@
+package txmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// recordFees keeps the fees wallet index built its batch with, for ShowFees
+// and the run report.
+func (t *TxManager) recordFees(index int, fees *ethwallet.FeeData) {
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+	if t.appliedFees == nil {
+		t.appliedFees = make(map[int]*ethwallet.FeeData)
+	}
+	t.appliedFees[index] = fees
+}
+
+// logFeeTable prints the fee parameters the batches were built with. Wallets
+// that used identical fees share a row, so shared fees print a single line.
+func (t *TxManager) logFeeTable() {
+	t.Mu.Lock()
+	type row struct {
+		fees    *ethwallet.FeeData
+		wallets []int
+	}
+	rows := make(map[string]*row)
+	for index, fees := range t.appliedFees {
+		key := fmt.Sprintf("%s/%s/%s/%d", fees.BaseFee, fees.TipCap, fees.MaxFeeCap, fees.GasLimit)
+		if rows[key] == nil {
+			rows[key] = &row{fees: fees}
+		}
+		rows[key].wallets = append(rows[key].wallets, index)
+	}
+	t.Mu.Unlock()
+
+	if len(rows) == 0 {
+		logger.Infof("Fees applied: none, no batch was built")
+		return
+	}
+
+	sorted := make([]*row, 0, len(rows))
+	for _, r := range rows {
+		sort.Ints(r.wallets)
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].wallets[0] < sorted[j].wallets[0] })
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WALLETS\tBASE FEE (gwei)\tTIP (gwei)\tMAX FEE (gwei)\tGAS LIMIT")
+	for _, r := range sorted {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", walletList(r.wallets),
+			ethwallet.FormatBalance(r.fees.BaseFee, ethwallet.UnitGwei),
+			ethwallet.FormatBalance(r.fees.TipCap, ethwallet.UnitGwei),
+			ethwallet.FormatBalance(r.fees.MaxFeeCap, ethwallet.UnitGwei),
+			r.fees.GasLimit)
+	}
+	w.Flush()
+
+	logger.Infof("Fees applied:")
+	for _, line := range strings.Split(strings.TrimRight(b.String(), "\n"), "\n") {
+		logger.Infof("  %s", line)
+	}
+}
+
+// walletList abbreviates sorted wallet indices, e.g. "0-3,7".
+func walletList(indices []int) string {
+	var parts []string
+	for i := 0; i < len(indices); {
+		j := i
+		for j+1 < len(indices) && indices[j+1] == indices[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, fmt.Sprint(indices[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", indices[i], indices[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,68 @@
+package txmanager
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// GasUsage compares the gas limits of the mined transactions of one type
+// with the gas they actually used, to tune how much gas is reserved.
+type GasUsage struct {
+	Transactions int    `json:"transactions"`
+	GasLimit     uint64 `json:"gas_limit"`
+	GasUsed      uint64 `json:"gas_used"`
+	// OverEstimate is by how much, in percent of GasUsed, the limits
+	// exceeded the gas used on average.
+	OverEstimate float64 `json:"over_estimate_percent"`
+}
+
+// add counts one mined transaction with its gas limit and gas used.
+func (g *GasUsage) add(limit, used uint64) {
+	g.Transactions++
+	g.GasLimit += limit
+	g.GasUsed += used
+	if g.GasUsed > 0 {
+		g.OverEstimate = (float64(g.GasLimit) - float64(g.GasUsed)) / float64(g.GasUsed) * 100
+	}
+}
+
+// txTypeName names a transaction type for the gas usage of the summary and report.
+func txTypeName(txType uint8) string {
+	switch txType {
+	case types.LegacyTxType:
+		return "legacy"
+	case types.AccessListTxType:
+		return "access_list"
+	case types.DynamicFeeTxType:
+		return "dynamic_fee"
+	case types.BlobTxType:
+		return "blob"
+	case types.SetCodeTxType:
+		return "set_code"
+	}
+	return "unknown"
+}
+
+// recordGasUsage adds the gas a mined transaction used to the totals of its
+// type. Callers must hold Mu.
+func (t *TxManager) recordGasUsage(tx *types.Transaction, receipt *types.Receipt) {
+	if t.gasUsage == nil {
+		t.gasUsage = make(map[string]GasUsage)
+	}
+	name := txTypeName(tx.Type())
+	usage := t.gasUsage[name]
+	usage.add(tx.Gas(), receipt.GasUsed)
+	t.gasUsage[name] = usage
+}
+
+// logGasUsage prints the gas used against the gas limits, per transaction type.
+func logGasUsage(usage map[string]GasUsage) {
+	for _, name := range slices.Sorted(maps.Keys(usage)) {
+		u := usage[name]
+		logger.Infof("Gas used (%s): %d of %d reserved over %d transactions, limits %.1f%% above use on average",
+			name, u.GasUsed, u.GasLimit, u.Transactions, u.OverEstimate)
+	}
+}
@@ -0,0 +1,118 @@
+package txmanager
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// headWatcher follows the chain head for every confirmation of a run: one
+// goroutine polls the node every ReceiptPollInterval, however many
+// transactions are waiting, and ends the block deadlines the head reaches.
+type headWatcher struct {
+	mu sync.Mutex
+	// head is the highest head seen; nil until the first successful poll.
+	head      *big.Int
+	deadlines map[*blockDeadline]struct{}
+}
+
+// blockDeadline ends its context, through cancel, once the head is blocks
+// past start. A nil start is taken from the next head seen.
+type blockDeadline struct {
+	start  *big.Int
+	blocks uint64
+	cancel context.CancelCauseFunc
+}
+
+// watchHeads starts polling the head of client until ctx ends.
+func (t *TxManager) watchHeads(ctx context.Context, client rpc.EthBackend) *headWatcher {
+	interval := t.ReceiptPollInterval
+	if interval <= 0 {
+		interval = DefaultReceiptPollInterval
+	}
+
+	w := &headWatcher{deadlines: make(map[*blockDeadline]struct{})}
+	go func() {
+		for {
+			header, err := client.HeaderByNumber(ctx, nil)
+			switch {
+			case err != nil:
+				if ctx.Err() == nil {
+					logger.Debugf("failed to fetch the chain head, retrying: %v", err)
+				}
+			case header == nil || header.Number == nil:
+				// Some nodes answer with a null header and no error, e.g. while syncing.
+				logger.Debugf("node returned no chain head, retrying")
+			default:
+				w.advance(header.Number)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.clock().After(interval):
+			}
+		}
+	}()
+	return w
+}
+
+// Head returns the highest head seen so far, or nil before the first.
+func (w *headWatcher) Head() *big.Int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.head
+}
+
+// advance records head and ends the deadlines it reaches.
+func (w *headWatcher) advance(head *big.Int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.head != nil && head.Cmp(w.head) <= 0 {
+		return
+	}
+	w.head = head
+	for d := range w.deadlines {
+		if d.reached(head) {
+			delete(w.deadlines, d)
+		}
+	}
+}
+
+// reached starts d at head if it had no start yet, and ends it if head is
+// far enough past its start.
+func (d *blockDeadline) reached(head *big.Int) bool {
+	if d.start == nil {
+		d.start = head
+	}
+	if new(big.Int).Sub(head, d.start).Cmp(new(big.Int).SetUint64(d.blocks)) < 0 {
+		return false
+	}
+	d.cancel(fmt.Errorf("not mined within %d blocks of block %s", d.blocks, d.start))
+	return true
+}
+
+// withBlockTimeout returns a copy of ctx that ends once the head is blocks
+// past start; context.Cause then says so. A nil start counts from the head
+// the watcher knows, or else the next one it sees.
+func (w *headWatcher) withBlockTimeout(ctx context.Context, start *big.Int, blocks uint64) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	d := &blockDeadline{start: start, blocks: blocks, cancel: cancel}
+
+	w.mu.Lock()
+	if w.head == nil || !d.reached(w.head) {
+		w.deadlines[d] = struct{}{}
+	}
+	w.mu.Unlock()
+
+	return ctx, func() {
+		w.mu.Lock()
+		delete(w.deadlines, d)
+		w.mu.Unlock()
+		cancel(context.Canceled)
+	}
+}
@@ -0,0 +1,88 @@
+package txmanager
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// headBackend is a NullBackend whose chain head is set by the test. Until
+// it is, HeaderByNumber answers a null header with no error, as a syncing
+// node can.
+type headBackend struct {
+	*rpc.NullBackend
+	head  atomic.Pointer[big.Int]
+	polls atomic.Int64
+}
+
+func (b *headBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	b.polls.Add(1)
+	head := b.head.Load()
+	if head == nil {
+		return nil, nil
+	}
+	return &types.Header{Number: head}, nil
+}
+
+func TestHeadWatcherSharedPoll(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	m := &TxManager{Clock: clock, ReceiptPollInterval: time.Second}
+	client := &headBackend{NullBackend: rpc.NewNullBackend()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := m.watchHeads(ctx, client)
+	waitForWaiters(t, clock, 1)
+	if head := w.Head(); head != nil {
+		t.Fatalf("Head() = %v after a null header, want nil", head)
+	}
+
+	// None of these knew the head when broadcast; they count from the next one.
+	const waiting = 10
+	confirms := make([]context.Context, waiting)
+	for i := range confirms {
+		var stop context.CancelFunc
+		confirms[i], stop = w.withBlockTimeout(context.Background(), nil, 2)
+		defer stop()
+	}
+
+	poll := func(head int64) {
+		t.Helper()
+		client.head.Store(big.NewInt(head))
+		clock.Advance(time.Second)
+		waitForWaiters(t, clock, 1)
+	}
+	poll(100)
+	poll(101)
+	for i, ctx := range confirms {
+		if ctx.Err() != nil {
+			t.Fatalf("confirmation %d ended 1 block past its start: %v", i, context.Cause(ctx))
+		}
+	}
+
+	// This one was broadcast at block 99, so block 101 is already too late.
+	late, stop := w.withBlockTimeout(context.Background(), big.NewInt(99), 2)
+	defer stop()
+	if late.Err() == nil {
+		t.Error("confirmation broadcast 2 blocks ago did not end")
+	}
+
+	poll(102)
+	for i, ctx := range confirms {
+		if ctx.Err() == nil {
+			t.Fatalf("confirmation %d still waiting 2 blocks past its start", i)
+		}
+		if cause := context.Cause(ctx).Error(); !strings.Contains(cause, "within 2 blocks of block 100") {
+			t.Errorf("confirmation %d ended with %q", i, cause)
+		}
+	}
+	if polls := client.polls.Load(); polls != 4 {
+		t.Errorf("head polled %d times for %d confirmations over 4 intervals, want 4", polls, waiting)
+	}
+}
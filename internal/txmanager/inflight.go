@@ -0,0 +1,47 @@
+package txmanager
+
+import (
+	"context"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// initInflight creates the slots that bound, under MaxInflight, how many
+// broadcast transactions may await their confirmation at once. Without
+// confirmation tracking nothing stays in flight and there is no bound.
+func (t *TxManager) initInflight() {
+	if t.MaxInflight <= 0 || !(t.WaitReceipts || t.EscalateAfter > 0) {
+		return
+	}
+	t.inflight = make(chan struct{}, t.MaxInflight)
+	logger.Infof("At most %d transactions in flight awaiting confirmation", t.MaxInflight)
+}
+
+// acquireInflight blocks until a transaction may be broadcast under
+// MaxInflight. It fails only when ctx ends first.
+func (t *TxManager) acquireInflight(ctx context.Context) error {
+	if t.inflight == nil {
+		return nil
+	}
+	select {
+	case t.inflight <- struct{}{}:
+		return nil
+	default:
+	}
+
+	logger.Debugf("%d transactions in flight, waiting for a confirmation", t.MaxInflight)
+	select {
+	case t.inflight <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// releaseInflight frees the slot of a transaction that is confirmed, or will
+// never be.
+func (t *TxManager) releaseInflight() {
+	if t.inflight != nil {
+		<-t.inflight
+	}
+}
@@ -0,0 +1,63 @@
+package txmanager
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// JitterMode selects how retry delays are randomized so that broadcasts failing
+// together do not all retry at the same instant.
+type JitterMode string
+
+const (
+	// JitterNone waits exactly RetryDelay.
+	JitterNone JitterMode = "none"
+	// JitterFull waits a uniformly random time in [0, RetryDelay).
+	JitterFull JitterMode = "full"
+	// JitterEqual waits RetryDelay/2 plus a uniformly random time in
+	// [0, RetryDelay/2): retries still back off by at least half the delay.
+	JitterEqual JitterMode = "equal"
+)
+
+// ParseJitterMode validates a -retry-jitter value; "" means JitterNone.
+func ParseJitterMode(s string) (JitterMode, error) {
+	switch mode := JitterMode(s); mode {
+	case "":
+		return JitterNone, nil
+	case JitterNone, JitterFull, JitterEqual:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown retry jitter %q (want none, full or equal)", s)
+}
+
+// jitterStream is the RNG stream of retry jitter, apart from the payload and
+// per-wallet value streams.
+const jitterStream = payloadStream - 1
+
+// initRetryJitter seeds the RNG that jitters retry delays from Seed.
+func (t *TxManager) initRetryJitter() {
+	if t.RetryJitter == "" || t.RetryJitter == JitterNone || t.Retries == 0 {
+		return
+	}
+	t.resolveSeed()
+	t.jitterRng = rand.New(rand.NewPCG(t.Seed, jitterStream))
+}
+
+// retryDelay returns how long to wait before the next retry under RetryJitter.
+func (t *TxManager) retryDelay() time.Duration {
+	if t.jitterRng == nil || t.RetryDelay <= 0 {
+		return t.RetryDelay
+	}
+
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+	switch t.RetryJitter {
+	case JitterFull:
+		return time.Duration(t.jitterRng.Int64N(int64(t.RetryDelay)))
+	case JitterEqual:
+		half := t.RetryDelay / 2
+		return half + time.Duration(t.jitterRng.Int64N(int64(t.RetryDelay-half)))
+	}
+	return t.RetryDelay
+}
@@ -0,0 +1,50 @@
+package txmanager
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryDelayBounds(t *testing.T) {
+	const (
+		delay = 100 * time.Millisecond
+		draws = 1000
+	)
+	for _, c := range []struct {
+		mode     JitterMode
+		min, max time.Duration // max is exclusive, but for JitterNone
+	}{
+		{mode: JitterNone, min: delay, max: delay},
+		{mode: JitterFull, min: 0, max: delay},
+		{mode: JitterEqual, min: delay / 2, max: delay},
+	} {
+		t.Run(string(c.mode), func(t *testing.T) {
+			drawDelays := func() []time.Duration {
+				m := &TxManager{Mu: &sync.Mutex{}, Seed: 42, Retries: 3, RetryDelay: delay, RetryJitter: c.mode}
+				m.initRetryJitter()
+				delays := make([]time.Duration, draws)
+				for i := range delays {
+					delays[i] = m.retryDelay()
+				}
+				return delays
+			}
+
+			delays := drawDelays()
+			for _, d := range delays {
+				if d < c.min || d > c.max || (d == c.max && c.min != c.max) {
+					t.Fatalf("delay %v outside [%v, %v)", d, c.min, c.max)
+				}
+			}
+			if c.mode != JitterNone {
+				if lo, hi := slices.Min(delays), slices.Max(delays); hi-lo < (c.max-c.min)/2 {
+					t.Errorf("delays only spread over [%v, %v]", lo, hi)
+				}
+			}
+			if !slices.Equal(delays, drawDelays()) {
+				t.Error("the same seed drew different delays")
+			}
+		})
+	}
+}
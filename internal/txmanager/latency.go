@@ -0,0 +1,201 @@
+package txmanager
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the broadcast latency
+// histogram, from a local node's answer to a congested remote RPC's.
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// ParseLatencyBuckets parses comma-separated, strictly increasing durations
+// such as "10ms,50ms,250ms,1s" into histogram bucket bounds.
+func ParseLatencyBuckets(spec string) ([]time.Duration, error) {
+	var bounds []time.Duration
+	for _, field := range strings.Split(spec, ",") {
+		bound, err := time.ParseDuration(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid latency bucket %q: %w", field, err)
+		}
+		if bound <= 0 {
+			return nil, fmt.Errorf("latency bucket %v must be > 0", bound)
+		}
+		if len(bounds) > 0 && bound <= bounds[len(bounds)-1] {
+			return nil, fmt.Errorf("latency buckets must increase: %v follows %v", bound, bounds[len(bounds)-1])
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds, nil
+}
+
+// LatencyBucket is one bucket of a LatencyHistogram: how many broadcasts took
+// at most UpperBound milliseconds and more than the previous bucket's bound.
+// The last bucket has no upper bound, left at 0.
+type LatencyBucket struct {
+	UpperBound float64 `json:"le_ms,omitempty"`
+	Count      int     `json:"count"`
+}
+
+// LatencyHistogram is the distribution of broadcast latencies, in
+// milliseconds. P95 is estimated from the buckets.
+type LatencyHistogram struct {
+	Count   int             `json:"count"`
+	Min     float64         `json:"min_ms"`
+	Mean    float64         `json:"mean_ms"`
+	P95     float64         `json:"p95_ms"`
+	Max     float64         `json:"max_ms"`
+	Buckets []LatencyBucket `json:"buckets"`
+}
+
+// latencyHistogram counts latencies into fixed buckets, so a run of any
+// size takes the same memory; only the count, sum and extremes are kept
+// besides the bucket counts.
+type latencyHistogram struct {
+	bounds   []time.Duration
+	counts   []int // one per bound, then the overflow
+	count    int
+	sum      time.Duration
+	min, max time.Duration
+}
+
+func newLatencyHistogram(bounds []time.Duration) *latencyHistogram {
+	return &latencyHistogram{bounds: bounds, counts: make([]int, len(bounds)+1)}
+}
+
+// observe counts one latency.
+func (h *latencyHistogram) observe(d time.Duration) {
+	i, _ := slices.BinarySearch(h.bounds, d)
+	h.counts[i]++
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.count++
+	h.sum += d
+}
+
+// quantile estimates the q-th quantile (0-1) by interpolating linearly within
+// the bucket it falls in, bounded by the smallest and largest latencies seen.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := q * float64(h.count)
+	seen := 0
+	for i, n := range h.counts {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+		lower, upper := h.min, h.max
+		if i > 0 {
+			lower = max(lower, h.bounds[i-1])
+		}
+		if i < len(h.bounds) {
+			upper = min(upper, h.bounds[i])
+		}
+		frac := (rank - float64(seen)) / float64(n)
+		return lower + time.Duration(frac*float64(upper-lower))
+	}
+	return h.max
+}
+
+// snapshot converts the histogram for the summary and report.
+func (h *latencyHistogram) snapshot() *LatencyHistogram {
+	out := &LatencyHistogram{
+		Count:   h.count,
+		Min:     milliseconds(h.min),
+		P95:     milliseconds(h.quantile(0.95)),
+		Max:     milliseconds(h.max),
+		Buckets: make([]LatencyBucket, len(h.counts)),
+	}
+	if h.count > 0 {
+		out.Mean = milliseconds(h.sum / time.Duration(h.count))
+	}
+	for i, n := range h.counts {
+		out.Buckets[i].Count = n
+		if i < len(h.bounds) {
+			out.Buckets[i].UpperBound = milliseconds(h.bounds[i])
+		}
+	}
+	return out
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// recordLatency adds the latency of one broadcast to the histogram over
+// LatencyBuckets. Callers must hold Mu.
+func (t *TxManager) recordLatency(d time.Duration) {
+	if t.latency == nil {
+		bounds := t.LatencyBuckets
+		if len(bounds) == 0 {
+			bounds = DefaultLatencyBuckets
+		}
+		t.latency = newLatencyHistogram(bounds)
+	}
+	t.latency.observe(d)
+}
+
+// latencySnapshot returns the latency histogram so far, nil before any
+// broadcast. Callers must hold Mu.
+func (t *TxManager) latencySnapshot() *LatencyHistogram {
+	if t.latency == nil {
+		return nil
+	}
+	return t.latency.snapshot()
+}
+
+// logLatency prints the spread of the broadcast latencies.
+func logLatency(h *LatencyHistogram) {
+	logger.Infof("Broadcast latency: min %.1fms, mean %.1fms, p95 ~%.1fms, max %.1fms over %d broadcasts",
+		h.Min, h.Mean, h.P95, h.Max, h.Count)
+}
+
+// writeLatencyCSV writes the buckets of h to path, one "le_ms,count" row per
+// bucket; the last bucket's bound is +Inf.
+func writeLatencyCSV(path string, h *LatencyHistogram) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create latency histogram: %w", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"le_ms", "count"})
+	for i, bucket := range h.Buckets {
+		bound := "+Inf"
+		if i < len(h.Buckets)-1 {
+			bound = strconv.FormatFloat(bucket.UpperBound, 'f', -1, 64)
+		}
+		w.Write([]string{bound, strconv.Itoa(bucket.Count)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write latency histogram: %w", err)
+	}
+	return f.Close()
+}
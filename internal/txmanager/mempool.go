@@ -0,0 +1,44 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// DefaultVerifyMempoolDelay is how long after a broadcast its presence is checked.
+const DefaultVerifyMempoolDelay = 2 * time.Second
+
+// verifyInMempool waits VerifyMempoolDelay after p was accepted and asks the
+// node for it by hash. A transaction the node no longer knows, neither pending
+// nor mined, was silently dropped and is counted as Vanished.
+func (t *TxManager) verifyInMempool(ctx context.Context, client rpc.EthBackend, p pendingTx) {
+	delay := t.VerifyMempoolDelay
+	if delay <= 0 {
+		delay = DefaultVerifyMempoolDelay
+	}
+	select {
+	case <-ctx.Done():
+		return
+	case <-t.clock().After(delay):
+	}
+
+	_, pending, err := client.TransactionByHash(ctx, p.tx.Hash())
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		t.Mu.Lock()
+		t.Vanished++
+		t.Mu.Unlock()
+		p.log.Warnf("transaction %s from %s was accepted but the node no longer knows it", p.tx.Hash(), p.wallet.Name())
+	case err != nil:
+		p.log.Debugf("could not check %s in the mempool: %v", p.tx.Hash(), err)
+	default:
+		t.Mu.Lock()
+		t.InMempool++
+		t.Mu.Unlock()
+		p.log.Debugf("transaction %s found by the node (pending: %v)", p.tx.Hash(), pending)
+	}
+}
@@ -0,0 +1,60 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+)
+
+// resyncNonce handles a broadcast of p rejected with err for a nonce too low:
+// up to NonceRetries times it resyncs the wallet's nonce from the chain,
+// re-signs the transaction with it and sends it again. It returns p with the
+// transaction sent last and the outcome of that send.
+func (t *TxManager) resyncNonce(ctx context.Context, p pendingTx, err error) (pendingTx, error) {
+	for attempt := 1; attempt <= t.NonceRetries && errors.Is(err, ethwallet.ErrNonceTooLow); attempt++ {
+		nonce, fetchErr := t.nextNonce(ctx, p)
+		if fetchErr != nil {
+			return p, fmt.Errorf("%w (nonce resync failed: %v)", err, fetchErr)
+		}
+		tx, signErr := p.wallet.ResignWithNonce(p.tx, nonce)
+		if signErr != nil {
+			return p, fmt.Errorf("%w (nonce resync failed: %v)", err, signErr)
+		}
+
+		p.log.Debugf("nonce %d of %s too low, re-signed with nonce %d as %s (%d/%d)",
+			p.tx.Nonce(), p.wallet.Name(), nonce, tx.Hash(), attempt, t.NonceRetries)
+		p.tx = tx
+		t.Mu.Lock()
+		t.NonceResyncs++
+		t.Mu.Unlock()
+
+		err = ethwallet.ClassifySendError(t.sendWithRetry(ctx, p))
+	}
+	return p, err
+}
+
+// nextNonce returns the nonce to re-sign a transaction of p's wallet with: the
+// wallet's pending nonce, or the one after the last nonce handed out by an
+// earlier resync if that is higher, so concurrent resyncs never share a nonce.
+func (t *TxManager) nextNonce(ctx context.Context, p pendingTx) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	nonce, err := p.wallet.Client.PendingNonceAt(ctx, p.wallet.Address)
+	if err != nil {
+		return 0, err
+	}
+
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+	if t.resyncedNonces == nil {
+		t.resyncedNonces = make(map[int]uint64)
+	}
+	if next, ok := t.resyncedNonces[p.index]; ok && next > nonce {
+		nonce = next
+	}
+	t.resyncedNonces[p.index] = nonce + 1
+	return nonce, nil
+}
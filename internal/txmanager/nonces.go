@@ -0,0 +1,40 @@
+package txmanager
+
+import (
+	"context"
+	"time"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// syncNonces fetches the pending nonce of every wallet in one phase before the
+// batches are built, with at most BalanceConcurrency requests in flight, and
+// hands each to its wallet's batch options. A wallet whose fetch failed is
+// logged and fetches its nonce itself when it builds its batch.
+func (t *TxManager) syncNonces(client rpc.EthBackend, wallets []*ethwallet.WalletInfo, walletOpts []ethwallet.BatchOptions) {
+	if len(wallets) == 0 {
+		return
+	}
+	failed := make([]error, len(wallets))
+	forEachWallet(len(wallets), t.BalanceConcurrency, func(i int) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		nonce, err := client.PendingNonceAt(ctx, wallets[i].Address)
+		cancel()
+		if err != nil {
+			failed[i] = err
+			return
+		}
+		walletOpts[i].Nonce = &nonce
+	})
+
+	synced := len(wallets)
+	for i, err := range failed {
+		if err != nil {
+			synced--
+			logger.Warnf("failed to get nonce of %s, it will be fetched with its batch: %v", wallets[i].Name(), err)
+		}
+	}
+	logger.Debugf("Synchronized the nonces of %d/%d wallets", synced, len(wallets))
+}
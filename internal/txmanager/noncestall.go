@@ -0,0 +1,89 @@
+package txmanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// nonceWatch is what the stall watchdog knows of one wallet: its on-chain
+// nonce when last seen to move, and whether the stall was reported.
+type nonceWatch struct {
+	nonce  uint64
+	since  time.Time
+	warned bool
+}
+
+// startNonceWatch checks, every quarter of NonceStallTimeout until ctx ends,
+// the on-chain nonce of every wallet that still has accepted transactions
+// unmined, and warns about each one whose nonce has not moved for
+// NonceStallTimeout. The returned function stops the watchdog.
+func (t *TxManager) startNonceWatch(ctx context.Context, client rpc.EthBackend) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		watched := make(map[int]*nonceWatch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.clock().After(t.NonceStallTimeout / 4):
+			}
+			t.checkNonces(ctx, client, watched)
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// checkNonces updates watched with the on-chain nonces of the wallets whose
+// highest accepted nonce is not mined yet, and warns about stalled ones.
+func (t *TxManager) checkNonces(ctx context.Context, client rpc.EthBackend, watched map[int]*nonceWatch) {
+	t.Mu.Lock()
+	highest := make(map[int]uint64, len(t.walletNonce))
+	for i, nonce := range t.walletNonce {
+		if t.walletSent[i] > 0 {
+			highest[i] = nonce
+		}
+	}
+	t.Mu.Unlock()
+
+	now := t.clock().Now()
+	for i, accepted := range highest {
+		if i >= len(t.Wallets) {
+			continue
+		}
+		wallet := t.Wallets[i]
+		nonce, err := client.NonceAt(ctx, wallet.Address, nil)
+		if err != nil {
+			logger.Debugf("nonce stall check of %s failed: %v", wallet.Name(), err)
+			continue
+		}
+
+		w, ok := watched[i]
+		switch {
+		case nonce > accepted:
+			// Everything the wallet got accepted is mined.
+			delete(watched, i)
+		case !ok || nonce != w.nonce:
+			watched[i] = &nonceWatch{nonce: nonce, since: now}
+		case !w.warned && now.Sub(w.since) >= t.NonceStallTimeout:
+			w.warned = true
+			t.Mu.Lock()
+			t.NonceStalls++
+			t.Mu.Unlock()
+			logger.Warnf("==================== NONCE STALL ====================")
+			logger.Warnf("wallet %s: on-chain nonce stuck at %d for %v with transactions up to nonce %d accepted;",
+				wallet.Name(), nonce, now.Sub(w.since).Round(time.Second), accepted)
+			logger.Warnf("they are not being mined (stuck mempool or fees too low?)")
+			logger.Warnf("=====================================================")
+		}
+	}
+}
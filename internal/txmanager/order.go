@@ -0,0 +1,82 @@
+package txmanager
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// BroadcastOrder selects the order in which built transactions are broadcast.
+type BroadcastOrder string
+
+const (
+	// OrderSequential broadcasts each wallet's transactions together, in the
+	// order the wallets finished building them.
+	OrderSequential BroadcastOrder = "sequential"
+	// OrderShuffle broadcasts every transaction in a random order drawn from
+	// Seed, so a wallet's later nonces may reach the node before earlier ones.
+	OrderShuffle BroadcastOrder = "shuffle"
+	// OrderInterleave takes one transaction from each wallet in turn, keeping
+	// every wallet's transactions in nonce order.
+	OrderInterleave BroadcastOrder = "interleave"
+)
+
+// ParseBroadcastOrder validates a -broadcast-order value; "" means OrderSequential.
+func ParseBroadcastOrder(s string) (BroadcastOrder, error) {
+	switch order := BroadcastOrder(s); order {
+	case "":
+		return OrderSequential, nil
+	case OrderSequential, OrderShuffle, OrderInterleave:
+		return order, nil
+	}
+	return "", fmt.Errorf("unknown broadcast order %q (want sequential, shuffle or interleave)", s)
+}
+
+// orderStream is the RNG stream of the shuffled broadcast order, apart from
+// the payload, jitter and per-wallet value streams.
+const orderStream = payloadStream - 2
+
+// orderBroadcasts rearranges txs according to BroadcastOrder.
+func (t *TxManager) orderBroadcasts(txs []pendingTx) []pendingTx {
+	if t.BroadcastOrder == "" || t.BroadcastOrder == OrderSequential {
+		return txs
+	}
+
+	// Wallets finish building in no particular order: sort by wallet and
+	// nonce first so the same seed always gives the same order.
+	slices.SortStableFunc(txs, func(a, b pendingTx) int {
+		if c := cmp.Compare(a.index, b.index); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.tx.Nonce(), b.tx.Nonce())
+	})
+
+	switch t.BroadcastOrder {
+	case OrderShuffle:
+		t.resolveSeed()
+		rng := rand.New(rand.NewPCG(t.Seed, orderStream))
+		rng.Shuffle(len(txs), func(i, j int) { txs[i], txs[j] = txs[j], txs[i] })
+	case OrderInterleave:
+		var wallets [][]pendingTx
+		for i, p := range txs {
+			if i == 0 || p.index != txs[i-1].index {
+				wallets = append(wallets, nil)
+			}
+			wallets[len(wallets)-1] = append(wallets[len(wallets)-1], p)
+		}
+		ordered := make([]pendingTx, 0, len(txs))
+		for round := 0; len(ordered) < len(txs); round++ {
+			for _, w := range wallets {
+				if round < len(w) {
+					ordered = append(ordered, w[round])
+				}
+			}
+		}
+		txs = ordered
+	}
+	logger.Infof("Broadcasting %d transactions in %s order", len(txs), t.BroadcastOrder)
+	return txs
+}
@@ -0,0 +1,40 @@
+package txmanager
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// payloadStream is the RNG stream of the -data-size payload, kept apart from
+// the per-wallet value streams, which are numbered by wallet index.
+const payloadStream = math.MaxUint64
+
+// resolveSeed picks a random Seed when none was given, once, and logs it so a
+// run drawing random values or data can be repeated.
+func (t *TxManager) resolveSeed() {
+	if t.seedLogged {
+		return
+	}
+	if t.Seed == 0 {
+		t.Seed = rand.Uint64()
+	}
+	t.seedLogged = true
+	logger.Infof("Random seed: %d (pass -seed %d to repeat this run)", t.Seed, t.Seed)
+}
+
+// payload returns the DataSize random bytes every transaction carries as
+// calldata, drawn from Seed so the same seed always gives the same payload.
+func (t *TxManager) payload() []byte {
+	if t.DataSize <= 0 {
+		return nil
+	}
+	t.resolveSeed()
+	rng := rand.New(rand.NewPCG(t.Seed, payloadStream))
+	data := make([]byte, t.DataSize)
+	for i := range data {
+		data[i] = byte(rng.Uint32())
+	}
+	return data
+}
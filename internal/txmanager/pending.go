@@ -0,0 +1,88 @@
+package txmanager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// WalletPending is the mempool state of one wallet: its nonce at the latest
+// block and its pending nonce, which counts transactions not mined yet.
+type WalletPending struct {
+	Address      common.Address
+	Label        string
+	Nonce        uint64
+	PendingNonce uint64
+	// Err is why the nonces could not be fetched, if they could not.
+	Err error
+}
+
+// Name returns the wallet's label, or its address when it has none.
+func (w WalletPending) Name() string {
+	if w.Label != "" {
+		return w.Label
+	}
+	return w.Address.Hex()
+}
+
+// Pending is how many of the wallet's transactions wait in the mempool.
+func (w WalletPending) Pending() uint64 {
+	if w.PendingNonce < w.Nonce {
+		return 0
+	}
+	return w.PendingNonce - w.Nonce
+}
+
+// PendingTransactions reports, for each of the WalletsNumber wallets, how many
+// transactions the node still holds unmined, to diagnose stuck transactions
+// before or after a run. Nonces are fetched with at most BalanceConcurrency
+// requests in flight; a wallet whose nonces fail has Err set.
+func (t *TxManager) PendingTransactions() ([]WalletPending, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool, err := t.dialPool(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer pool.Close()
+	client := pool.Primary().Client
+
+	chainID, err := client.NetworkID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	logNetwork(chainID)
+
+	wallets, err := t.deriveWallets(client, t.WalletsNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]WalletPending, len(wallets))
+	forEachWallet(len(wallets), t.BalanceConcurrency, func(i int) {
+		results[i] = fetchPending(client, wallets[i].Address)
+		results[i].Label = t.WalletLabels[i]
+	})
+
+	return results, nil
+}
+
+// fetchPending reads the latest-block and pending nonces of address.
+func fetchPending(client rpc.EthBackend, address common.Address) WalletPending {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result := WalletPending{Address: address}
+	if result.Nonce, result.Err = client.NonceAt(ctx, address, nil); result.Err != nil {
+		result.Err = fmt.Errorf("failed to get nonce: %w", result.Err)
+		return result
+	}
+	if result.PendingNonce, result.Err = client.PendingNonceAt(ctx, address); result.Err != nil {
+		result.Err = fmt.Errorf("failed to get pending nonce: %w", result.Err)
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// checkFunding verifies that every wallet holds enough to pay for at least one
+// transaction (value + gasLimit * maxFeeCap) at the fees it will send with,
+// its fee override included. walletOpts holds the options of each wallet, by
+// index; balances are fetched with at most BalanceConcurrency in flight.
+// It returns an error listing every underfunded address, or nil when all wallets can send.
+func (t *TxManager) checkFunding(client rpc.EthBackend, wallets []*ethwallet.WalletInfo, walletOpts []ethwallet.BatchOptions) error {
+	if len(wallets) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Self-transfers cost the same for every wallet, so price one and reuse it.
+	shared := walletOpts[0].Fees
+	if shared == nil {
+		var err error
+		shared, err = wallets[0].FetchFeeData(ctx, walletOpts[0])
+		if err != nil {
+			return fmt.Errorf("failed to fetch fee data for funding check: %w", err)
+		}
+	}
+
+	// Each wallet writes only its own index of problems.
+	problems := make([]string, len(wallets))
+	forEachWallet(len(wallets), t.BalanceConcurrency, func(i int) {
+		opts := walletOpts[i]
+		fees := shared
+		if opts.Fees != nil {
+			fees = opts.Fees
+		}
+		if opts.Override != nil {
+			fees = opts.Override.Apply(fees)
+		}
+		required := fees.TxCost(opts.TxValue())
+
+		balance, err := client.BalanceAt(ctx, wallets[i].Address, nil)
+		if err != nil {
+			problems[i] = fmt.Sprintf("%s (balance unavailable: %v)", wallets[i].Name(), err)
+		} else if balance.Cmp(required) < 0 {
+			problems[i] = fmt.Sprintf("%s (balance %s wei, a transaction costs %s wei)", wallets[i].Name(), balance, required)
+		}
+	})
+
+	var underfunded []string
+	for _, problem := range problems {
+		if problem != "" {
+			underfunded = append(underfunded, problem)
+		}
+	}
+	if len(underfunded) > 0 {
+		return fmt.Errorf("%d/%d wallets cannot afford a single transaction:\n  %s",
+			len(underfunded), len(wallets), strings.Join(underfunded, "\n  "))
+	}
+
+	logger.Infof("Funding check passed: all %d wallets can afford a transaction", len(wallets))
+	return nil
+}
+
+// ErrGasPriceTooHigh is returned by Run when the network's gas price is above MaxGasPrice.
+var ErrGasPriceTooHigh = errors.New("gas price above the sanity ceiling")
+
+// checkGasPrice fails with ErrGasPriceTooHigh when the current gas price, the
+// latest base fee plus the suggested tip (or the suggested gas price for a
+// legacy Signer), exceeds MaxGasPrice, so a fee spike aborts the run before
+// anything is signed.
+func (t *TxManager) checkGasPrice(client rpc.EthBackend) error {
+	if t.MaxGasPrice == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var price *big.Int
+	if t.Signer.Legacy() {
+		var err error
+		if price, err = client.SuggestGasPrice(ctx); err != nil {
+			return fmt.Errorf("failed to suggest gas price for the gas price check: %w", err)
+		}
+	} else {
+		header, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to fetch latest header for the gas price check: %w", err)
+		}
+		if header == nil || header.BaseFee == nil {
+			return errors.New("node returned no base fee for the gas price check")
+		}
+		tip, err := client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to suggest tip cap for the gas price check: %w", err)
+		}
+		price = new(big.Int).Add(header.BaseFee, tip)
+	}
+
+	gwei := func(wei *big.Int) string { return ethwallet.FormatBalance(wei, ethwallet.UnitGwei) }
+	if price.Cmp(t.MaxGasPrice) > 0 {
+		return fmt.Errorf("%w: the network asks %s gwei per gas, over the %s gwei ceiling; not sending", ErrGasPriceTooHigh, gwei(price), gwei(t.MaxGasPrice))
+	}
+	logger.Infof("Gas price check passed: %s gwei per gas, ceiling %s gwei", gwei(price), gwei(t.MaxGasPrice))
+	return nil
+}
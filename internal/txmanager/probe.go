@@ -0,0 +1,103 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// MempoolProbe is the outcome of ProbeMempool.
+type MempoolProbe struct {
+	// Accepted is how many transactions the node took before refusing more.
+	Accepted int
+	// Limited is true when the node refused with a mempool-full error, so
+	// Accepted is its per-account limit; false means the probe stopped at its maximum.
+	Limited bool
+	// Err is the rejection that ended the probe, if any.
+	Err error
+}
+
+// ProbeMempool discovers how many pending transactions the node accepts from a
+// single account: it sends self-transfers from the first wallet, one nonce
+// after the other, until the node rejects one as mempool-full or max is reached.
+// Any other rejection ends the probe with an error.
+func (t *TxManager) ProbeMempool(max int) (MempoolProbe, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool, err := t.dialPool(ctx)
+	if err != nil {
+		return MempoolProbe{}, err
+	}
+	defer pool.Close()
+	t.pool = pool
+	client := pool.Primary().Client
+
+	chainID, err := client.NetworkID(ctx)
+	if err != nil {
+		return MempoolProbe{}, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	logNetwork(chainID)
+	if err := t.confirmMainnet(chainID); err != nil {
+		return MempoolProbe{}, err
+	}
+
+	wallets, err := t.deriveWallets(client, 1)
+	if err != nil {
+		return MempoolProbe{}, err
+	}
+	wallet := wallets[0]
+
+	opts := ethwallet.BatchOptions{
+		TipPercentile: t.TipPercentile,
+		TipBlocks:     t.TipBlocks,
+		Value:         t.Value,
+		MaxFee:        t.MaxFee,
+		MinTip:        t.MinTip,
+		GasMultiplier: t.GasMultiplier,
+		Oracle:        t.GasOracle,
+		Signer:        t.Signer,
+	}
+	fees, err := wallet.FetchFeeData(ctx, opts)
+	if err != nil {
+		return MempoolProbe{}, fmt.Errorf("failed to fetch fee data: %w", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, wallet.Address)
+	if err != nil {
+		return MempoolProbe{}, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	logger.Infof("Probing mempool limit with up to %d transactions from %s", max, wallet.Address)
+
+	var probe MempoolProbe
+	for probe.Accepted < max {
+		tx, err := wallet.SignTransaction(t.Signer, chainID, nonce+uint64(probe.Accepted), fees, &wallet.Address, opts.TxValue(), nil)
+		if err != nil {
+			return probe, err
+		}
+
+		sendCtx, cancelSend := context.WithTimeout(context.Background(), 10*time.Second)
+		err = ethwallet.ClassifySendError(client.SendTransaction(sendCtx, tx))
+		cancelSend()
+
+		if errors.Is(err, ethwallet.ErrMempoolFull) {
+			probe.Limited = true
+			probe.Err = err
+			return probe, nil
+		}
+		if err != nil {
+			probe.Err = err
+			return probe, fmt.Errorf("probe stopped after %d transactions by a non-mempool error: %w", probe.Accepted, err)
+		}
+
+		probe.Accepted++
+		if probe.Accepted%100 == 0 {
+			logger.Infof("%d transactions pending so far", probe.Accepted)
+		}
+	}
+	return probe, nil
+}
@@ -0,0 +1,57 @@
+package txmanager
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// Prompter asks the user a yes/no question and reports whether they agreed.
+type Prompter func(question string) bool
+
+// promptStdin asks question on stdout and reads the answer from stdin.
+// Anything but "y" or "yes" (including EOF, e.g. a closed stdin in CI) declines.
+func promptStdin(question string) bool {
+	return askYesNo(os.Stdin, os.Stdout, question)
+}
+
+func askYesNo(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(out)
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// confirmMainnet asks for confirmation before sending on a known mainnet.
+// It returns an error when the user declines; AssumeYes skips the question.
+func (t *TxManager) confirmMainnet(chainID *big.Int) error {
+	if t.AssumeYes || !rpc.IsMainnet(chainID) {
+		return nil
+	}
+	if t.TypedData != nil && t.Verifier == nil || t.DumpRawTxs != "" {
+		// Signing-only runs never broadcast.
+		return nil
+	}
+
+	prompt := t.Prompt
+	if prompt == nil {
+		prompt = promptStdin
+	}
+	question := fmt.Sprintf("About to send %d transactions on %s (chain ID %s). Continue?", t.TxNumber, rpc.ChainName(chainID), chainID)
+	if !prompt(question) {
+		return fmt.Errorf("run on %s not confirmed (pass -yes to skip the confirmation)", rpc.ChainName(chainID))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package txmanager
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// initWalletLimiters gives each of count wallets its own limiter of
+// PerWalletTPS sends per second, or none when PerWalletTPS is 0.
+func (t *TxManager) initWalletLimiters(count int) {
+	if t.PerWalletTPS <= 0 {
+		return
+	}
+	t.walletLimiters = make([]*rate.Limiter, count)
+	for i := range t.walletLimiters {
+		t.walletLimiters[i] = rate.NewLimiter(rate.Limit(t.PerWalletTPS), 1)
+	}
+}
+
+// waitWalletTurn blocks until wallet index may send again under PerWalletTPS.
+// It fails only when ctx ends first, giving the turn back. The limiter is
+// read and waited on through the manager's clock.
+func (t *TxManager) waitWalletTurn(ctx context.Context, index int) error {
+	if index >= len(t.walletLimiters) {
+		return nil
+	}
+	limiter := t.walletLimiters[index]
+	now := t.clock().Now()
+	turn := limiter.ReserveN(now, 1)
+	if !turn.OK() {
+		return fmt.Errorf("per-wallet rate limit of %v tx/s admits no send", limiter.Limit())
+	}
+	delay := turn.DelayFrom(now)
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		turn.CancelAt(t.clock().Now())
+		return ctx.Err()
+	case <-t.clock().After(delay):
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWalletTurnFollowsClock(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	m := &TxManager{Clock: clock, PerWalletTPS: 2}
+	m.initWalletLimiters(2)
+	ctx := context.Background()
+
+	if err := m.waitWalletTurn(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	// Wallet 1 has its own limiter, so its first send does not wait either.
+	if err := m.waitWalletTurn(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- m.waitWalletTurn(ctx, 0) }()
+	waitForWaiters(t, clock, 1)
+	clock.Advance(499 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("second send of wallet 0 went ahead before 1/PerWalletTPS passed")
+	default:
+	}
+	clock.Advance(time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	go func() { done <- m.waitWalletTurn(cancelled, 0) }()
+	waitForWaiters(t, clock, 1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("waitWalletTurn error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,76 @@
+package txmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+)
+
+// FeeRecord is the gas limit and fees, in Wei, a wallet built its batch with.
+type FeeRecord struct {
+	GasLimit uint64   `json:"gas_limit"`
+	BaseFee  *big.Int `json:"base_fee"`
+	Tip      *big.Int `json:"tip"`
+	MaxFee   *big.Int `json:"max_fee"`
+}
+
+// LoadReplayFees reads the fees each wallet used in the run that wrote the
+// JSON report at path, for ReplayFees.
+func LoadReplayFees(path string) (map[int]*ethwallet.FeeData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	if report.Version != ReportVersion {
+		return nil, fmt.Errorf("report %s has version %d, want %d", path, report.Version, ReportVersion)
+	}
+	if len(report.Fees) == 0 {
+		return nil, fmt.Errorf("report %s records no fees: no batch was built in that run", path)
+	}
+
+	fees := make(map[int]*ethwallet.FeeData, len(report.Fees))
+	for i, r := range report.Fees {
+		if r.GasLimit == 0 || r.BaseFee == nil || r.Tip == nil || r.MaxFee == nil {
+			return nil, fmt.Errorf("report %s: fees of wallet %d need gas_limit, base_fee, tip and max_fee", path, i)
+		}
+		if r.Tip.Sign() < 0 || r.BaseFee.Sign() < 0 || r.MaxFee.Cmp(r.Tip) < 0 {
+			return nil, fmt.Errorf("report %s: fees of wallet %d are inconsistent (tip %s, max fee %s)", path, i, r.Tip, r.MaxFee)
+		}
+		fees[i] = &ethwallet.FeeData{GasLimit: r.GasLimit, BaseFee: r.BaseFee, TipCap: r.Tip, MaxFeeCap: r.MaxFee}
+	}
+	return fees, nil
+}
+
+// replayFees builds every wallet's batch with the fees of ReplayFees instead
+// of querying the node. It fails when a wallet of this run has no recorded fees.
+func (t *TxManager) replayFees(walletOpts []ethwallet.BatchOptions) error {
+	for i := range walletOpts {
+		fees, ok := t.ReplayFees[i]
+		if !ok {
+			return fmt.Errorf("replayed fees have no entry for wallet %d; the report covers %d wallets", i, len(t.ReplayFees))
+		}
+		walletOpts[i].Fees = fees
+	}
+	return nil
+}
+
+// feeRecords converts the fees the batches were built with for the report.
+// Callers must hold Mu.
+func (t *TxManager) feeRecords() map[int]FeeRecord {
+	if len(t.appliedFees) == 0 {
+		return nil
+	}
+	records := make(map[int]FeeRecord, len(t.appliedFees))
+	for i, fees := range t.appliedFees {
+		records[i] = FeeRecord{GasLimit: fees.GasLimit, BaseFee: fees.BaseFee, Tip: fees.TipCap, MaxFee: fees.MaxFeeCap}
+	}
+	return records
+}
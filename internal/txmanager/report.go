@@ -0,0 +1,128 @@
+package txmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// ReportVersion identifies the layout of RunReport; it is bumped whenever a
+// field changes meaning so readers can reject reports they don't understand.
+const ReportVersion = 1
+
+// RunReport is the machine-readable outcome of a run, written as JSON by -report.
+type RunReport struct {
+	Version   int       `json:"version"`
+	Label     string    `json:"label,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+
+	Wallets  int `json:"wallets"`
+	TxNumber int `json:"txns"`
+
+	WarmupAccepted int `json:"warmup_accepted,omitempty"`
+	WarmupFailed   int `json:"warmup_failed,omitempty"`
+
+	Success     int `json:"success"`
+	Failed      int `json:"failed"`
+	Drained     int `json:"drained,omitempty"`
+	Cancelled   int `json:"cancelled,omitempty"`
+	Confirmed   int `json:"confirmed,omitempty"`
+	Reverted    int `json:"reverted,omitempty"`
+	Unconfirmed int `json:"unconfirmed,omitempty"`
+	Escalated   int `json:"escalated,omitempty"`
+	Escalations int `json:"escalations,omitempty"`
+	NonceStalls int `json:"nonce_stalls,omitempty"`
+	InMempool   int `json:"in_mempool,omitempty"`
+	Vanished    int `json:"vanished,omitempty"`
+
+	// Retried counts the extra attempts of transient failures, NonceResyncs
+	// the transactions re-signed after a nonce too low.
+	Retried      int `json:"retried,omitempty"`
+	NonceResyncs int `json:"nonce_resyncs,omitempty"`
+
+	// ByType splits Success and Failed by transaction type.
+	ByType map[string]TypeCount `json:"by_type,omitempty"`
+	// PerWallet compares the transactions each wallet was given with
+	// WalletTxCounts to those it got accepted, by wallet index.
+	PerWallet map[int]WalletCount `json:"per_wallet,omitempty"`
+	// Endpoints compares the share of accepted transactions of each endpoint
+	// with RpcWeights, by URL.
+	Endpoints map[string]EndpointShare `json:"endpoints,omitempty"`
+	// Faucet is the outcome of the faucet request of each wallet.
+	Faucet []FaucetRecord `json:"faucet,omitempty"`
+
+	// Fees are the gas limit and fees each wallet built its batch with, by
+	// wallet index; -replay-fees reads them back.
+	Fees map[int]FeeRecord `json:"fees,omitempty"`
+	// GasUsage compares gas limits with the gas used by confirmed
+	// transactions, keyed by transaction type.
+	GasUsage map[string]GasUsage `json:"gas_usage,omitempty"`
+	// Latency is the distribution of broadcast latencies.
+	Latency *LatencyHistogram `json:"latency,omitempty"`
+
+	// Reverts are the mined transactions that reverted, with their reasons.
+	Reverts []RevertRecord `json:"reverts,omitempty"`
+	// SimulationReverts are the transactions Simulate skipped, without a block.
+	SimulationReverts []RevertRecord `json:"simulation_reverts,omitempty"`
+	// Errors are the first failures captured with CaptureErrors.
+	Errors []ErrorRecord `json:"errors,omitempty"`
+}
+
+// Report snapshots the counters of the run into a RunReport.
+func (t *TxManager) Report() RunReport {
+	t.Mu.Lock()
+	defer t.Mu.Unlock()
+
+	return RunReport{
+		Version:        ReportVersion,
+		Label:          t.Label,
+		StartedAt:      t.startedAt,
+		EndedAt:        t.clock().Now(),
+		Wallets:        t.WalletsNumber,
+		TxNumber:       t.TxNumber,
+		WarmupAccepted: t.WarmupAccepted,
+		WarmupFailed:   t.WarmupFailed,
+		Success:        t.Success,
+		Failed:         t.Failed,
+		Drained:        t.Drained,
+		Cancelled:      t.Cancelled,
+		Confirmed:      t.Confirmed,
+		Reverted:       t.Reverted,
+		Unconfirmed:    t.Unconfirmed,
+		Escalated:      t.Escalated,
+		Escalations:    t.Escalations,
+		NonceStalls:    t.NonceStalls,
+		InMempool:      t.InMempool,
+		Vanished:       t.Vanished,
+		Retried:        t.Retried,
+		NonceResyncs:   t.NonceResyncs,
+		ByType:         maps.Clone(t.typeCounts),
+		PerWallet:      t.walletCounts(),
+		Endpoints:      t.endpointShares(),
+		Faucet:         slices.Clone(t.faucetRecords),
+		Fees:           t.feeRecords(),
+		GasUsage:       maps.Clone(t.gasUsage),
+		Latency:        t.latencySnapshot(),
+		Reverts:        slices.Clone(t.reverts),
+		Errors:         slices.Clone(t.capturedErrors),
+
+		SimulationReverts: slices.Clone(t.simulationReverts),
+	}
+}
+
+// writeReport writes the run report to ReportPath as indented JSON.
+func (t *TxManager) writeReport() error {
+	data, err := json.MarshalIndent(t.Report(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run report: %w", err)
+	}
+
+	if err := os.WriteFile(t.ReportPath, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	return nil
+}
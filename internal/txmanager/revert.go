@@ -0,0 +1,84 @@
+package txmanager
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// RevertRecord is a transaction that was mined but reverted, with the reason
+// found by replaying it.
+type RevertRecord struct {
+	WalletIndex int    `json:"wallet_index"`
+	Wallet      string `json:"wallet"`
+	Nonce       uint64 `json:"nonce"`
+	TxHash      string `json:"tx_hash"`
+	Block       uint64 `json:"block"`
+	Reason      string `json:"reason"`
+}
+
+// revertReason replays the call of p with eth_call on the state before the
+// block receipt was mined in and decodes why it reverts: the Error(string) or
+// Panic(uint256) of Solidity, or else the raw revert data or the node's
+// message. The block's own state already has p applied.
+func revertReason(ctx context.Context, client rpc.EthBackend, p pendingTx, receipt *types.Receipt) string {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	msg := ethereum.CallMsg{
+		From:  p.wallet.Address,
+		To:    p.tx.To(),
+		Gas:   p.tx.Gas(),
+		Value: p.tx.Value(),
+		Data:  p.tx.Data(),
+	}
+	var parent *big.Int
+	if receipt.BlockNumber != nil && receipt.BlockNumber.Sign() > 0 {
+		parent = new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	}
+	_, err := client.CallContract(ctx, msg, parent)
+	if err == nil {
+		return "unknown (the call no longer reverts when replayed)"
+	}
+	return decodeRevert(err)
+}
+
+// decodeRevert extracts the reason from the error of a reverted eth_call.
+func decodeRevert(err error) string {
+	var dataErr gethrpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if raw, decodeErr := hexutil.Decode(data); decodeErr == nil && len(raw) > 0 {
+				if reason, unpackErr := abi.UnpackRevert(raw); unpackErr == nil {
+					return reason
+				}
+				return fmt.Sprintf("%s (revert data 0x%s)", err, hex.EncodeToString(raw))
+			}
+		}
+	}
+	return err.Error()
+}
+
+// logRevertReasons prints how many transactions reverted for each reason.
+func logRevertReasons(records []RevertRecord) {
+	counts := make(map[string]int)
+	for _, r := range records {
+		counts[r.Reason]++
+	}
+	for _, reason := range slices.Sorted(maps.Keys(counts)) {
+		logger.Infof("  reverted %d times: %s", counts[reason], reason)
+	}
+}
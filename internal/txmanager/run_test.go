@@ -0,0 +1,218 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// nilChainBackend is a NullBackend that does not know its chain ID.
+type nilChainBackend struct {
+	*rpc.NullBackend
+}
+
+func (nilChainBackend) NetworkID(ctx context.Context) (*big.Int, error) {
+	return nil, nil
+}
+
+// stuckBackend is a NullBackend whose broadcasts block until release is
+// closed, whether or not their context ends, like a node slow to answer. It
+// records when it is closed.
+type stuckBackend struct {
+	*rpc.NullBackend
+	release  chan struct{}
+	closed   chan struct{}
+	sending  atomic.Int64
+	sent     atomic.Int64
+	lateSend atomic.Int64 // sends still in flight when Close was called
+}
+
+func (b *stuckBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.sending.Add(1)
+	defer b.sending.Add(-1)
+	<-b.release
+	b.sent.Add(1)
+	return b.NullBackend.SendTransaction(context.Background(), tx)
+}
+
+func (b *stuckBackend) Close() {
+	b.lateSend.Store(b.sending.Load())
+	close(b.closed)
+}
+
+// testManager returns a manager sending txs transactions from wallets
+// wallets of testMnemonic through client.
+func testManager(client rpc.EthBackend, wallets, txs int) *TxManager {
+	return &TxManager{
+		Mu:            &sync.Mutex{},
+		Mnemonic:      testMnemonic,
+		WalletsNumber: wallets,
+		TxNumber:      txs,
+		dial: func(ctx context.Context) (*rpc.RpcPool, error) {
+			return poolOf(client), nil
+		},
+	}
+}
+
+func TestRunNilChainID(t *testing.T) {
+	client := nilChainBackend{rpc.NewNullBackend()}
+	m := testManager(client, 1, 1)
+	m.OnSigned = func(*types.Transaction) { t.Error("signed a transaction without a chain ID") }
+
+	if err := m.Run(); !errors.Is(err, ethwallet.ErrNilChainID) {
+		t.Fatalf("Run error = %v, want %v", err, ethwallet.ErrNilChainID)
+	}
+}
+
+func TestRunWithoutWallets(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		setup func(*TxManager)
+	}{
+		{name: "zero wallets", setup: func(m *TxManager) { m.WalletsNumber = 0 }},
+		{name: "negative wallets", setup: func(m *TxManager) { m.WalletsNumber = -1 }},
+		{name: "nothing derived", setup: func(m *TxManager) { m.Mnemonic = "not a mnemonic" }},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			client := rpc.NewNullBackend()
+			m := testManager(client, 1, 10)
+			m.OnSigned = func(*types.Transaction) { t.Error("signed a transaction without wallets") }
+			c.setup(m)
+
+			if err := m.Run(); !errors.Is(err, ErrNoWallets) {
+				t.Fatalf("Run error = %v, want %v", err, ErrNoWallets)
+			}
+		})
+	}
+}
+
+func TestRunTimeoutKeepsPoolOpen(t *testing.T) {
+	client := &stuckBackend{
+		NullBackend: rpc.NewNullBackend(),
+		release:     make(chan struct{}),
+		closed:      make(chan struct{}),
+	}
+	clock := NewManualClock(time.Unix(0, 0))
+	m := testManager(client, 2, 4)
+	m.Clock = clock
+	m.RunTimeout = time.Minute
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Run()
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for client.sending.Load() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d broadcasts in flight, want 4", client.sending.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(m.RunTimeout)
+	<-done
+
+	select {
+	case <-client.closed:
+		t.Fatal("pool closed while timed out broadcasts were still sending")
+	default:
+	}
+	close(client.release)
+	select {
+	case <-client.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pool never closed after the outstanding broadcasts finished")
+	}
+	if n := client.lateSend.Load(); n != 0 {
+		t.Errorf("pool closed with %d broadcasts in flight", n)
+	}
+	if got := client.sent.Load(); got != 4 {
+		t.Errorf("%d broadcasts finished, want 4", got)
+	}
+}
+
+// testRun returns the state of a run over client that nothing bounds.
+func testRun(t *testing.T, client rpc.EthBackend) *runState {
+	ctx, abort := context.WithCancelCause(context.Background())
+	t.Cleanup(func() { abort(nil) })
+	return &runState{ctx: ctx, abort: abort, confirmCtx: ctx, client: client, chainID: rpc.NullChainID}
+}
+
+func TestBuildTransactionsNonces(t *testing.T) {
+	client := rpc.NewNullBackend()
+	wallets, err := ethwallet.DeriveEthereumWalletsFromMnemonic(testMnemonic, ethwallet.DerivationPath{}, 3, client, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := testManager(client, len(wallets), 9)
+	r := testRun(t, client)
+	r.wallets = wallets
+	r.counts = []int{2, 3, 4}
+	r.walletOpts = make([]ethwallet.BatchOptions, len(wallets))
+	starts := []uint64{0, 7, 40}
+	for i := range r.walletOpts {
+		r.walletOpts[i] = ethwallet.BatchOptions{Fees: testFees, Nonce: &starts[i]}
+	}
+
+	txs, err := m.buildTransactions(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 9 {
+		t.Fatalf("built %d transactions, want 9", len(txs))
+	}
+	next := make(map[int]uint64)
+	for i, start := range starts {
+		next[i] = start
+	}
+	for _, p := range txs {
+		if p.wallet != wallets[p.index] {
+			t.Fatalf("transaction of wallet %d sent from %s", p.index, p.wallet.Address)
+		}
+		if got := p.tx.Nonce(); got != next[p.index] {
+			t.Errorf("wallet %d built nonce %d, want %d", p.index, got, next[p.index])
+		}
+		next[p.index]++
+	}
+	for i, start := range starts {
+		if built := next[i] - start; built != uint64(r.counts[i]) {
+			t.Errorf("wallet %d built %d transactions, want %d", i, built, r.counts[i])
+		}
+	}
+	if len(m.Wallets) != len(wallets) {
+		t.Errorf("Wallets holds %d wallets, want %d", len(m.Wallets), len(wallets))
+	}
+}
+
+func TestBroadcastTransactionsCounts(t *testing.T) {
+	for _, c := range []struct {
+		name          string
+		client        rpc.EthBackend
+		success, fail int
+	}{
+		{name: "accepted", client: rpc.NewNullBackend(), success: 5},
+		{name: "rejected", client: rejectingBackend{rpc.NewNullBackend()}, fail: 5},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			m := testManager(c.client, 1, 5)
+			m.pool = poolOf(c.client)
+			txs := signedTxs(t, testWallet(t, c.client), 5)
+
+			report := m.broadcastTransactions(testRun(t, c.client), txs)
+			if report.Success != c.success || report.Failed != c.fail {
+				t.Errorf("report counts %d accepted, %d failed; want %d, %d", report.Success, report.Failed, c.success, c.fail)
+			}
+			if report.Success != m.Success || report.Failed != m.Failed {
+				t.Errorf("report counts %d/%d, manager %d/%d", report.Success, report.Failed, m.Success, m.Failed)
+			}
+		})
+	}
+}
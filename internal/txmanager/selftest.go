@@ -0,0 +1,85 @@
+package txmanager
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+)
+
+// selfTestChainID is the chain the self-test transaction is signed for;
+// mainnet, so replay protection is checked against the most common chain ID.
+var selfTestChainID = big.NewInt(1)
+
+// SelfTestResult is the outcome of SelfTest: the address derived for the
+// first wallet and the sender recovered from the transaction it signed.
+type SelfTestResult struct {
+	Signer  ethwallet.Signer
+	Address common.Address
+	Sender  common.Address
+	Hash    common.Hash
+}
+
+// Passed reports whether the recovered sender is the derived wallet.
+func (r SelfTestResult) Passed() bool {
+	return r.Sender == r.Address
+}
+
+// SelfTest derives the first wallet, signs a fixed transaction with Signer
+// and recovers its sender with types.Sender, all offline. Unless the signer
+// is homestead, the signature must also be bound to its chain ID: recovering
+// it for another chain has to fail or give another address. It returns an
+// error when signing or recovery fails outright; a recovered sender that
+// differs from the wallet is reported by the result instead.
+func (t *TxManager) SelfTest() (SelfTestResult, error) {
+	result := SelfTestResult{Signer: t.Signer}
+	if result.Signer == "" {
+		result.Signer = ethwallet.SignerLondon
+	}
+
+	wallets, err := t.deriveWallets(nil, 1)
+	if err != nil {
+		return result, err
+	}
+	wallet := wallets[0]
+	result.Address = wallet.Address
+
+	fees := &ethwallet.FeeData{
+		GasLimit:  21000,
+		BaseFee:   big.NewInt(1_000_000_000),
+		TipCap:    big.NewInt(1_000_000_000),
+		MaxFeeCap: big.NewInt(3_000_000_000),
+	}
+	tx, err := wallet.SignTransaction(result.Signer, selfTestChainID, 0, fees, &wallet.Address, big.NewInt(ethwallet.TransferValue), nil)
+	if err != nil {
+		return result, err
+	}
+	result.Hash = tx.Hash()
+
+	result.Sender, err = types.Sender(selfTestSigner(tx, selfTestChainID), tx)
+	if err != nil {
+		return result, fmt.Errorf("failed to recover sender: %w", err)
+	}
+
+	if tx.Protected() {
+		otherChain := new(big.Int).Add(selfTestChainID, big.NewInt(1))
+		if replayed, err := types.Sender(types.LatestSignerForChainID(otherChain), tx); err == nil && replayed == result.Sender {
+			return result, errors.New("signature is not bound to its chain ID: it recovers the same sender on another chain")
+		}
+	} else if result.Signer != ethwallet.SignerHomestead {
+		return result, fmt.Errorf("signer %s produced a transaction without replay protection", result.Signer)
+	}
+	return result, nil
+}
+
+// selfTestSigner returns the signer that recovers tx's sender on chainID,
+// the homestead one for a transaction without replay protection.
+func selfTestSigner(tx *types.Transaction, chainID *big.Int) types.Signer {
+	if !tx.Protected() {
+		return types.HomesteadSigner{}
+	}
+	return types.LatestSignerForChainID(chainID)
+}
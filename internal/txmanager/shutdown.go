@@ -0,0 +1,45 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// ErrInterrupted is the cause of a run stopped through Interrupt.
+var ErrInterrupted = errors.New("interrupted")
+
+// watchInterrupt aborts the run with ErrInterrupted once Interrupt is closed
+// or receives, and returns the context confirmation waits run under. It ends
+// with runCtx, except after an interrupt: receipts of the transactions already
+// broadcast are then still collected for ShutdownGrace.
+func (t *TxManager) watchInterrupt(runCtx context.Context, abort context.CancelCauseFunc) (confirmCtx context.Context, cancel context.CancelFunc) {
+	ctx, stop := context.WithCancelCause(context.Background())
+
+	if t.Interrupt != nil {
+		go func() {
+			select {
+			case <-t.Interrupt:
+				abort(ErrInterrupted)
+			case <-runCtx.Done():
+			}
+		}()
+	}
+
+	context.AfterFunc(runCtx, func() {
+		cause := context.Cause(runCtx)
+		if !errors.Is(cause, ErrInterrupted) || t.ShutdownGrace <= 0 || !(t.WaitReceipts || t.EscalateAfter > 0) {
+			stop(cause)
+			return
+		}
+		logger.Warnf("interrupted: collecting pending confirmations for up to %v", t.ShutdownGrace)
+		select {
+		case <-t.clock().After(t.ShutdownGrace):
+			stop(cause)
+		case <-ctx.Done():
+		}
+	})
+
+	return ctx, func() { stop(context.Canceled) }
+}
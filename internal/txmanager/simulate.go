@@ -0,0 +1,72 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+// simulateBatch runs the transactions wallet index signed through eth_call at
+// the latest block and returns those to broadcast: all of them, or the ones
+// before the first that would revert, since later nonces could never be
+// mined without it. Each transaction skipped that way is recorded. A call
+// that fails for another reason, such as the node being unreachable, is
+// logged and the transaction kept.
+func (t *TxManager) simulateBatch(ctx context.Context, client rpc.EthBackend, index int, wallet *ethwallet.WalletInfo, log *logger.Logger, txs []*types.Transaction) []*types.Transaction {
+	for n, tx := range txs {
+		reason, reverts := simulate(ctx, client, log, wallet, tx)
+		if !reverts {
+			continue
+		}
+
+		log.Warnf("transaction %s (nonce %d) would revert, skipping it and %d later ones: %s", tx.Hash(), tx.Nonce(), len(txs)-n-1, reason)
+		t.Mu.Lock()
+		for _, skipped := range txs[n:] {
+			t.simulationReverts = append(t.simulationReverts, RevertRecord{
+				WalletIndex: index,
+				Wallet:      wallet.Name(),
+				Nonce:       skipped.Nonce(),
+				TxHash:      skipped.Hash().Hex(),
+				Reason:      reason,
+			})
+		}
+		t.Mu.Unlock()
+		return txs[:n]
+	}
+	return txs
+}
+
+// simulate calls tx from wallet with eth_call at the latest block. It
+// reports whether the call reverts, and why; errors that are not reverts are
+// logged and reported as not reverting.
+func simulate(ctx context.Context, client rpc.EthBackend, log *logger.Logger, wallet *ethwallet.WalletInfo, tx *types.Transaction) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	msg := ethereum.CallMsg{
+		From:  wallet.Address,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	_, err := client.CallContract(ctx, msg, nil)
+	if err == nil {
+		return "", false
+	}
+
+	var dataErr gethrpc.DataError
+	if !errors.As(err, &dataErr) && !strings.Contains(strings.ToLower(err.Error()), "revert") {
+		log.Warnf("failed to simulate transaction %s, sending it anyway: %v", tx.Hash(), err)
+		return "", false
+	}
+	return decodeRevert(err), true
+}
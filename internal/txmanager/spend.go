@@ -0,0 +1,88 @@
+package txmanager
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// txCost is the most tx can cost its sender: value + gas * gasFeeCap.
+func txCost(tx *types.Transaction) *big.Int {
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap())
+	return cost.Add(cost, tx.Value())
+}
+
+// errSpendCap is returned by escalate when the fee bump would take the run
+// past MaxSpend.
+var errSpendCap = errors.New("spend cap reached")
+
+// reserveSpend adds the cost of tx to the transactions issued so far and reports
+// whether it still fits under MaxSpend. A transaction that does not fit is not
+// counted. Callers must hold Mu.
+func (t *TxManager) reserveSpend(tx *types.Transaction) bool {
+	if t.MaxSpend == nil {
+		return true
+	}
+	return t.reserve(txCost(tx))
+}
+
+// bumpCost is what replacing tx with a copy paying percent more adds to its
+// cost: gas times the raise of the fee cap. Only one version of a
+// transaction can be mined, so that is all the replacement commits.
+func bumpCost(tx *types.Transaction, percent uint64) *big.Int {
+	raise := new(big.Int).Sub(ethwallet.BumpedFeeCap(tx, percent), tx.GasFeeCap())
+	return raise.Mul(raise, new(big.Int).SetUint64(tx.Gas()))
+}
+
+// reserveCost adds cost to the transactions issued so far and reports
+// whether it still fits under MaxSpend, like reserveSpend. Callers must hold Mu.
+func (t *TxManager) reserveCost(cost *big.Int) bool {
+	if t.MaxSpend == nil {
+		return true
+	}
+	return t.reserve(cost)
+}
+
+// releaseCost takes back cost reserved with reserveCost for a transaction
+// that was not issued after all. Callers must hold Mu.
+func (t *TxManager) releaseCost(cost *big.Int) {
+	if t.MaxSpend == nil {
+		return
+	}
+	t.issuedCost.Sub(t.issuedCost, cost)
+}
+
+// reserve adds cost to issuedCost unless that would exceed MaxSpend, which
+// must be set. Callers must hold Mu.
+func (t *TxManager) reserve(cost *big.Int) bool {
+	if t.issuedCost == nil {
+		t.issuedCost = new(big.Int)
+	}
+
+	next := new(big.Int).Add(t.issuedCost, cost)
+	if next.Cmp(t.MaxSpend) > 0 {
+		if !t.spendCapHit {
+			t.spendCapHit = true
+			logger.Warnf("spend cap of %s ether reached: no further transactions are issued", ethwallet.FormatEther(t.MaxSpend))
+		}
+		return false
+	}
+	t.issuedCost = next
+	return true
+}
+
+// recordSpend adds the cost of a broadcast the node accepted to Spent. Callers must hold Mu.
+func (t *TxManager) recordSpend(tx *types.Transaction) {
+	t.addSpent(txCost(tx))
+}
+
+// addSpent adds cost to Spent. Callers must hold Mu.
+func (t *TxManager) addSpent(cost *big.Int) {
+	if t.Spent == nil {
+		t.Spent = new(big.Int)
+	}
+	t.Spent.Add(t.Spent, cost)
+}
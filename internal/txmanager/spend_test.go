@@ -0,0 +1,68 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/mdtosif/icarus/internal/rpc"
+)
+
+func TestEscalateCountsAgainstSpendCap(t *testing.T) {
+	pool := nullPool(t)
+	client := pool.Primary().Client
+	wallet := testWallet(t, client)
+	tx, err := wallet.SignTransaction("", rpc.NullChainID, 0, testFees, &wallet.Address, big.NewInt(1), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bump := bumpCost(tx, 10)
+
+	for _, c := range []struct {
+		name  string
+		room  *big.Int
+		fits  bool
+		spent *big.Int
+	}{
+		{name: "bump fits", room: bump, fits: true, spent: bump},
+		{name: "bump exceeds cap", room: new(big.Int).Sub(bump, big.NewInt(1)), fits: false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			m := &TxManager{
+				Mu:              &sync.Mutex{},
+				EscalatePercent: 10,
+				MaxSpend:        new(big.Int).Add(txCost(tx), c.room),
+				pool:            pool,
+			}
+			if !m.reserveSpend(tx) {
+				t.Fatal("the original transaction does not fit under the cap")
+			}
+			issued := new(big.Int).Set(m.issuedCost)
+
+			replacement, err := m.escalate(context.Background(), pendingTx{wallet: wallet, tx: tx}, tx)
+			if !c.fits {
+				if !errors.Is(err, errSpendCap) {
+					t.Fatalf("escalate error = %v, want %v", err, errSpendCap)
+				}
+				if m.issuedCost.Cmp(issued) != 0 {
+					t.Errorf("issued cost moved from %s to %s for a bump that was not sent", issued, m.issuedCost)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("escalate: %v", err)
+			}
+			if got := new(big.Int).Sub(txCost(replacement), txCost(tx)); got.Cmp(bump) != 0 {
+				t.Errorf("replacement adds %s wei, bumpCost says %s", got, bump)
+			}
+			if m.issuedCost.Cmp(m.MaxSpend) != 0 {
+				t.Errorf("issued cost = %s, want the whole cap %s", m.issuedCost, m.MaxSpend)
+			}
+			if m.Spent.Cmp(c.spent) != 0 {
+				t.Errorf("Spent = %s, want %s", m.Spent, c.spent)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package txmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseWeightedSplit parses a -weighted-split spec into one weight per wallet.
+//
+// The spec is a JSON object mapping wallet groups to weights. A group is a
+// wallet index ("3"), an inclusive index range ("0-4") or "*" for every wallet
+// not named elsewhere. A group's weight is shared evenly by its wallets, e.g.
+// {"0-1": 80, "*": 20} sends 80% of the transactions from wallets 0 and 1.
+// Weights are relative: they are normalized, so they need not add up to 100.
+func ParseWeightedSplit(spec string, wallets int) ([]float64, error) {
+	var groups map[string]float64
+	if err := json.Unmarshal([]byte(spec), &groups); err != nil {
+		return nil, fmt.Errorf("invalid weighted split %q: %w", spec, err)
+	}
+	if len(groups) == 0 {
+		return nil, errors.New("weighted split names no wallets")
+	}
+
+	weights := make([]float64, wallets)
+	assigned := make([]bool, wallets)
+	rest := -1.0
+
+	for key, weight := range groups {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("weighted split: weight of %q must be a finite number >= 0", key)
+		}
+
+		if key == "*" {
+			rest = weight
+			continue
+		}
+
+		from, to, err := parseIndexRange(key)
+		if err != nil {
+			return nil, fmt.Errorf("weighted split: %w", err)
+		}
+		if to >= wallets {
+			return nil, fmt.Errorf("weighted split: wallet index %d out of range, only %d wallets", to, wallets)
+		}
+
+		share := weight / float64(to-from+1)
+		for i := from; i <= to; i++ {
+			if assigned[i] {
+				return nil, fmt.Errorf("weighted split: wallet %d is named by more than one group", i)
+			}
+			assigned[i] = true
+			weights[i] = share
+		}
+	}
+
+	if rest >= 0 {
+		var unassigned []int
+		for i := range weights {
+			if !assigned[i] {
+				unassigned = append(unassigned, i)
+			}
+		}
+		if len(unassigned) == 0 && rest > 0 {
+			return nil, errors.New(`weighted split: "*" has a weight but every wallet is already named`)
+		}
+		for _, i := range unassigned {
+			weights[i] = rest / float64(len(unassigned))
+		}
+	}
+
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return nil, errors.New("weighted split: weights must add up to more than 0")
+	}
+
+	return weights, nil
+}
+
+// parseIndexRange parses "3" or "0-4" into an inclusive index range.
+func parseIndexRange(key string) (int, int, error) {
+	fromStr, toStr, isRange := strings.Cut(key, "-")
+	from, err := strconv.Atoi(strings.TrimSpace(fromStr))
+	if err != nil || from < 0 {
+		return 0, 0, fmt.Errorf("invalid wallet index %q", key)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+
+	to, err := strconv.Atoi(strings.TrimSpace(toStr))
+	if err != nil || to < from {
+		return 0, 0, fmt.Errorf("invalid wallet range %q", key)
+	}
+	return from, to, nil
+}
+
+// splitTransactions distributes total transactions proportionally to weights,
+// handing the rounding remainder to the wallets with the largest fractional
+// shares so the counts always add up to total.
+func splitTransactions(total int, weights []float64) []int {
+	counts := make([]int, len(weights))
+
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+	if sum <= 0 {
+		return counts
+	}
+
+	fractions := make([]float64, len(weights))
+	assigned := 0
+	for i, w := range weights {
+		exact := float64(total) * w / sum
+		counts[i] = int(math.Floor(exact))
+		fractions[i] = exact - float64(counts[i])
+		assigned += counts[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return fractions[order[a]] > fractions[order[b]]
+	})
+
+	for i := 0; assigned < total; i++ {
+		counts[order[i%len(order)]]++
+		assigned++
+	}
+
+	return counts
+}
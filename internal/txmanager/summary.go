@@ -0,0 +1,50 @@
+package txmanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// startSummary logs a running summary of the broadcasts every SummaryInterval
+// until the returned function is called; that function also waits for the
+// loop to exit, so no progress line follows the final summary.
+func (t *TxManager) startSummary(ctx context.Context, total int) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	start := t.clock().Now()
+
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.clock().After(t.SummaryInterval):
+			}
+			t.logProgress(total, t.clock().Now().Sub(start))
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// logProgress prints one line with the broadcasts settled so far out of total
+// and the rate they were sent at over elapsed.
+func (t *TxManager) logProgress(total int, elapsed time.Duration) {
+	t.Mu.Lock()
+	success, failed, cancelled := t.Success, t.Failed, t.Cancelled
+	t.Mu.Unlock()
+
+	sent := success + failed + cancelled
+	var tps float64
+	if elapsed > 0 {
+		tps = float64(sent) / elapsed.Seconds()
+	}
+	logger.Infof("Progress: %d/%d sent (success %d, failed %d), %.1f tx/s over %v",
+		sent, total, success, failed, tps, elapsed.Round(time.Second))
+}
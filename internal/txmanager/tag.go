@@ -0,0 +1,21 @@
+package txmanager
+
+import "encoding/binary"
+
+// TagSize is the length of the tag TagData appends to the calldata of every
+// transaction: the wallet index and the nonce, big-endian, in 4 and 8 bytes.
+const TagSize = 12
+
+// dataTag returns the function tagging the transactions of wallet index with
+// TagData, nil when it is off.
+func (t *TxManager) dataTag(index int) func(nonce uint64) []byte {
+	if !t.TagData {
+		return nil
+	}
+	return func(nonce uint64) []byte {
+		tag := make([]byte, TagSize)
+		binary.BigEndian.PutUint32(tag, uint32(index))
+		binary.BigEndian.PutUint64(tag[4:], nonce)
+		return tag
+	}
+}
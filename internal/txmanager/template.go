@@ -0,0 +1,81 @@
+package txmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+)
+
+// LoadTxTemplate reads a transaction template: a JSON array describing the
+// transactions every wallet sends, in nonce order, e.g.
+//
+//	[{"to": "0x...", "value": "1000", "data": "0xa9059cbb...", "gas": 60000},
+//	 {"tip_gwei": "2", "max_fee_gwei": "40"}]
+//
+// value is in Wei and gas is the gas limit; omitted fields keep the run's
+// defaults, and an entry setting to or data without gas is estimated on its own.
+func LoadTxTemplate(path string) ([]ethwallet.TxSpec, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transaction template: %w", err)
+	}
+	var entries []struct {
+		To         string        `json:"to"`
+		Value      string        `json:"value"`
+		Data       hexutil.Bytes `json:"data"`
+		Gas        uint64        `json:"gas"`
+		TipGwei    string        `json:"tip_gwei"`
+		MaxFeeGwei string        `json:"max_fee_gwei"`
+	}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("invalid transaction template %s: %w", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("transaction template %s is empty", path)
+	}
+
+	specs := make([]ethwallet.TxSpec, len(entries))
+	for i, entry := range entries {
+		spec := &specs[i]
+		if entry.To != "" {
+			if !common.IsHexAddress(entry.To) {
+				return nil, fmt.Errorf("transaction template: entry %d: %q is not a hex address", i, entry.To)
+			}
+			to := common.HexToAddress(entry.To)
+			spec.To = &to
+		}
+		if entry.Value != "" {
+			value, ok := new(big.Int).SetString(entry.Value, 10)
+			if !ok || value.Sign() < 0 {
+				return nil, fmt.Errorf("transaction template: entry %d: invalid value %q", i, entry.Value)
+			}
+			spec.Value = value
+		}
+		if entry.Data != nil {
+			spec.Data = entry.Data
+		}
+		if entry.Gas > 0 && entry.Gas < 21000 {
+			return nil, fmt.Errorf("transaction template: entry %d: gas %d is below the 21000 of a transfer", i, entry.Gas)
+		}
+		spec.GasLimit = entry.Gas
+		if entry.TipGwei != "" {
+			if spec.TipCap, err = ethwallet.GweiToWei(entry.TipGwei); err != nil {
+				return nil, fmt.Errorf("transaction template: entry %d: %w", i, err)
+			}
+		}
+		if entry.MaxFeeGwei != "" {
+			if spec.MaxFeeCap, err = ethwallet.GweiToWei(entry.MaxFeeGwei); err != nil {
+				return nil, fmt.Errorf("transaction template: entry %d: %w", i, err)
+			}
+		}
+		if spec.TipCap != nil && spec.MaxFeeCap != nil && spec.TipCap.Cmp(spec.MaxFeeCap) > 0 {
+			return nil, fmt.Errorf("transaction template: entry %d: tip exceeds max fee", i)
+		}
+	}
+	return specs, nil
+}
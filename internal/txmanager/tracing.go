@@ -0,0 +1,44 @@
+package txmanager
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the per-transaction spans. Until a tracer provider is
+// installed (-otel-endpoint) it is a no-op.
+var tracer = otel.Tracer("github.com/mdtosif/icarus/internal/txmanager")
+
+// txAttributes identify a transaction on its spans.
+func txAttributes(p pendingTx) trace.SpanStartEventOption {
+	attrs := []attribute.KeyValue{
+		attribute.Int("wallet.index", p.index),
+		attribute.String("wallet.address", p.wallet.Address.Hex()),
+		attribute.Int64("tx.nonce", int64(p.tx.Nonce())),
+		attribute.String("tx.hash", p.tx.Hash().Hex()),
+	}
+	if p.wallet.Label != "" {
+		attrs = append(attrs, attribute.String("wallet.label", p.wallet.Label))
+	}
+	return trace.WithAttributes(attrs...)
+}
+
+// dropSpans ends the spans of transactions that will not be broadcast,
+// recording why.
+func dropSpans(txs []pendingTx, reason string) {
+	for _, p := range txs {
+		p.span.AddEvent("dropped", trace.WithAttributes(attribute.String("reason", reason)))
+		p.span.End()
+	}
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
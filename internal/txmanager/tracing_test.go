@@ -0,0 +1,70 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mdtosif/icarus/internal/rpc"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// rejectingBackend is a NullBackend that refuses every broadcast.
+type rejectingBackend struct {
+	*rpc.NullBackend
+}
+
+func (rejectingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return errors.New("rejected")
+}
+
+func TestDroppedTransactionsEndTheirSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	for _, c := range []struct {
+		name  string
+		setup func(*TxManager)
+		fails bool
+	}{
+		{name: "dump raw", setup: func(m *TxManager) { m.DumpRawTxs = filepath.Join(t.TempDir(), "raw.txt") }},
+		{name: "spend cap", setup: func(m *TxManager) { m.MaxSpend = big.NewInt(1e14) }},
+		{name: "warm-up failure", setup: func(m *TxManager) {
+			m.Warmup = true
+			m.dial = func(ctx context.Context) (*rpc.RpcPool, error) {
+				return poolOf(rejectingBackend{rpc.NewNullBackend()}), nil
+			}
+		}, fails: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			m := testManager(rpc.NewNullBackend(), 2, 6)
+			c.setup(m)
+			if err := m.Run(); (err != nil) != c.fails {
+				t.Fatalf("Run error = %v, want failure %v", err, c.fails)
+			}
+		})
+	}
+
+	started, ended := 0, 0
+	for _, span := range recorder.Started() {
+		if span.Name() == "transaction" {
+			started++
+		}
+	}
+	for _, span := range recorder.Ended() {
+		if span.Name() == "transaction" {
+			ended++
+		}
+	}
+	if started == 0 {
+		t.Fatal("no transaction spans recorded")
+	}
+	if ended != started {
+		t.Errorf("%d of %d transaction spans ended", ended, started)
+	}
+}
@@ -2,17 +2,30 @@ package txmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"math/big"
+	"math/rand/v2"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	ethwallet "github.com/mdtosif/icarus/internal/account"
 	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/metrics"
+	"github.com/mdtosif/icarus/internal/rpc"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 type TxManager struct {
+	// RpcUrl is a comma-separated list of endpoints. The first one serves every
+	// state query; broadcasts are spread over all of them.
 	RpcUrl        string
 	WalletsNumber int
 	TxNumber      int
@@ -22,11 +35,453 @@ type TxManager struct {
 	Failed        int
 	Success       int
 	Mu            *sync.Mutex
+
+	// PrivateKey, when set, is the hex key of the single wallet of the run,
+	// used instead of deriving wallets from Mnemonic.
+	PrivateKey string
+	// Derivation selects the account and change levels of the path wallets
+	// are derived from Mnemonic at; the zero value is m/44'/60'/0'/0/{i}.
+	Derivation ethwallet.DerivationPath
+
+	// RpcClientName is sent as the User-Agent of every RPC request and RpcTrace
+	// logs each outgoing JSON-RPC method and id (see rpc.DialOptions).
+	RpcClientName string
+	RpcTrace      bool
+	// RpcEndpoints holds per-endpoint timeouts and headers, keyed by URL.
+	RpcEndpoints map[string]rpc.EndpointConfig
+	// RpcWeights, when set, holds one weight per endpoint of RpcUrl and
+	// broadcasts pick endpoints at random in proportion to them, drawn from
+	// Seed, instead of in turn (see rpc.ParseWeights).
+	RpcWeights []float64
+
+	// WalletLabels names the wallets at these indices in logs and reports
+	// instead of their address (see ParseWalletLabels).
+	WalletLabels map[int]string
+
+	// ShowFees prints the base fee, tip, max fee and gas limit the batches were
+	// built with before they are broadcast. They are kept in appliedFees, by
+	// wallet index, for the run report either way.
+	ShowFees    bool
+	appliedFees map[int]*ethwallet.FeeData
+
+	// ExplorerURL, when set, is a block explorer template with a %s for the
+	// hash; every accepted transaction is logged with its link.
+	ExplorerURL string
+
+	// ReplayFees, when set, holds the fees to build each wallet's batch with,
+	// by wallet index, instead of querying the node (see LoadReplayFees).
+	ReplayFees map[int]*ethwallet.FeeData
+
+	// StreamWallets derives the wallets one after the other while the first
+	// ones already build their batches, instead of deriving them all up front.
+	// Wallet balances are not printed in this mode.
+	StreamWallets bool
+
+	// TipPercentile, when > 0, prices the tip from eth_feeHistory instead of
+	// the node's suggestion; TipBlocks is the number of blocks sampled.
+	TipPercentile float64
+	TipBlocks     uint64
+
+	// RunTimeout bounds the whole run; when it expires the outstanding goroutines
+	// are abandoned and the partial summary is printed. 0 disables the guard.
+	RunTimeout time.Duration
+
+	// RequireFunded aborts the run before building any transaction when a
+	// wallet cannot afford even one of them.
+	RequireFunded bool
+
+	// WaitReceipts makes every successful broadcast wait for its receipt.
+	// The node is polled every ReceiptPollInterval, for at most ReceiptTimeout per transaction.
+	WaitReceipts        bool
+	ReceiptPollInterval time.Duration
+	ReceiptTimeout      time.Duration
+	// ConfirmBlockTimeout, when > 0, also counts a transaction unconfirmed
+	// once the chain head is that many blocks past the head at its broadcast.
+	ConfirmBlockTimeout uint64
+	// Confirmation outcomes, guarded by Mu. Reverted is a subset of Confirmed.
+	Confirmed   int
+	Reverted    int
+	Unconfirmed int
+	// gasUsage compares gas limits with the gas used by the confirmed
+	// transactions, keyed by transaction type. Guarded by Mu.
+	gasUsage map[string]GasUsage
+	// reverts holds every reverted transaction with its revert reason. Guarded by Mu.
+	reverts []RevertRecord
+	// typeCounts splits the broadcast outcomes by transaction type. Guarded by Mu.
+	typeCounts map[string]TypeCount
+
+	// VerifyMempool asks the node for every accepted transaction by hash
+	// VerifyMempoolDelay after sending it (DefaultVerifyMempoolDelay when 0).
+	// InMempool counts those it still knows, pending or mined, and Vanished
+	// those it silently dropped. Both are guarded by Mu.
+	VerifyMempool      bool
+	VerifyMempoolDelay time.Duration
+	InMempool          int
+	Vanished           int
+
+	// EscalateAfter, when > 0, re-broadcasts a transaction that is still not mined
+	// after this long at the same nonce with fees raised by EscalatePercent.
+	EscalateAfter   time.Duration
+	EscalatePercent uint64
+	// Escalated counts transactions bumped at least once, Escalations every bump. Guarded by Mu.
+	Escalated   int
+	Escalations int
+
+	// Clock drives all pacing and timeouts; nil means the wall clock.
+	Clock Clock
+
+	// Label tags the run in the JSON report written to ReportPath (if set).
+	Label      string
+	ReportPath string
+
+	// SplitWeights, when set, holds one weight per wallet and TxNumber is
+	// distributed proportionally instead of evenly (see ParseWeightedSplit).
+	SplitWeights []float64
+	// WalletTxCounts, when set, holds the number of transactions of each
+	// wallet, replacing the split of TxNumber, which becomes their sum.
+	WalletTxCounts []int
+	// TxTemplate, when set, is the sequence of transactions every wallet
+	// sends (see LoadTxTemplate); TxNumber becomes its length times the
+	// number of wallets.
+	TxTemplate []ethwallet.TxSpec
+
+	// SharedFees estimates gas and fetches fee data once for all wallets
+	// instead of once per wallet; self-transfers cost the same from any wallet.
+	SharedFees bool
+
+	// Value is the Wei every transaction carries; nil uses ethwallet.TransferValue.
+	Value *big.Int
+
+	// To is the recipient of every transfer, a hex address or an ENS name
+	// resolved through the RPC; "" sends each wallet's transfers to itself.
+	To string
+
+	// MaxFee, when set, is the fee cap of every transaction in Wei and the tip is
+	// derived from it (see ethwallet.BatchOptions.MaxFee).
+	MaxFee *big.Int
+
+	// MaxGasPrice, when set, is a sanity ceiling in Wei: the run aborts before
+	// signing anything when the base fee plus the suggested tip exceeds it.
+	MaxGasPrice *big.Int
+
+	// ValueDist, when set, draws the value of every transaction from this
+	// distribution instead of using Value. Seed seeds the draws; 0 picks a
+	// random seed, which is logged so the run can be repeated.
+	ValueDist *ethwallet.ValueDistribution
+	Seed      uint64
+	// seedLogged is set once Seed is resolved and logged.
+	seedLogged bool
+
+	// DataSize, when > 0, makes every transaction carry that many random bytes
+	// of calldata drawn from Seed, to test how payload size affects gas and acceptance.
+	DataSize int
+	// TagData appends the wallet index and nonce of every transaction to its
+	// calldata (see TagSize), to find each one in a block explorer.
+	TagData bool
+
+	// MinTip, when set, is the floor in Wei every transaction's tip is raised to.
+	MinTip *big.Int
+	// GasMultiplier, when > 0, scales every gas estimate instead of adding
+	// ethwallet.GasBuffer to it.
+	GasMultiplier float64
+
+	// GasOracle, when set, prices transactions from an external gas oracle,
+	// falling back to the node when it fails.
+	GasOracle *rpc.OracleClient
+
+	// Faucet, when set, is asked to fund every wallet before the run, which
+	// waits up to FaucetWait for the funds to land (see prefund).
+	Faucet        *rpc.FaucetClient
+	FaucetWait    time.Duration
+	faucetRecords []FaucetRecord
+
+	// MaxSpend, when set, caps the Wei the run may commit: transactions are issued
+	// only while the sum of their worst-case cost (value + gas * maxFee) stays under it.
+	// The fee an escalation adds counts too; a bump that does not fit is not sent.
+	// Spent is the worst-case cost of the broadcasts the node accepted, guarded by Mu.
+	MaxSpend    *big.Int
+	Spent       *big.Int
+	issuedCost  *big.Int
+	spendCapHit bool
+
+	// FeeOverrides replaces the default fees of the wallets at these indices
+	// (see ParseFeeOverrides).
+	FeeOverrides map[int]ethwallet.FeeOverride
+
+	// BalanceBlock is the block wallet balances are reported at; nil means latest.
+	BalanceBlock *big.Int
+	// BalanceConcurrency bounds the per-wallet requests in flight at once:
+	// balances, nonces and faucet fundings.
+	BalanceConcurrency int
+	// BalanceUnit is the unit balances are printed in: wei, gwei or ether (default).
+	BalanceUnit string
+
+	// ExpectDrain counts "insufficient funds" rejections as Drained instead of
+	// Failed, for runs that deliberately send more than the wallets can pay for.
+	ExpectDrain bool
+	Drained     int
+
+	// FailFast aborts the run on the first failed broadcast, leaving transactions
+	// not yet dispatched unsent. Broadcasts cut short by the abort are counted as Cancelled.
+	FailFast    bool
+	Cancelled   int
+	failFastErr error
+
+	// ConsecutiveFailureLimit, when > 0, aborts the run once that many
+	// broadcasts in a row have failed; any success resets the count.
+	ConsecutiveFailureLimit int
+	consecutiveFailures     int
+	breakerErr              error
+
+	// Simulate runs every signed transaction through eth_call at the latest
+	// block and skips those that would revert, with the later nonces of their
+	// wallet. The skipped transactions are kept with their reasons, guarded by Mu.
+	Simulate          bool
+	simulationReverts []RevertRecord
+
+	// NonceStallTimeout, when > 0, watches the on-chain nonce of every wallet
+	// with accepted transactions unmined and warns when it has not moved for
+	// that long. NonceStalls counts the warnings, guarded by Mu.
+	NonceStallTimeout time.Duration
+	NonceStalls       int
+
+	// PerWalletTPS, when > 0, caps how many transactions per second each wallet
+	// sends, on top of the global pacing set by WaitMilis: a wallet never
+	// exceeds this rate, and the run as a whole never launches broadcasts faster
+	// than one per WaitMilis. It keeps single accounts under per-account mempool
+	// limits while many wallets together still reach the global rate.
+	PerWalletTPS   float64
+	walletLimiters []*rate.Limiter
+
+	// MaxInflight, when > 0, bounds how many broadcast transactions may await
+	// their confirmation at once (WaitReceipts or EscalateAfter): dispatching
+	// blocks until a confirmation frees a slot.
+	MaxInflight int
+	inflight    chan struct{}
+
+	// AdaptiveErrorRate, when > 0, throttles dispatching while the RPC
+	// struggles, AIMD-style: after every AdaptiveWindow broadcasts, the send
+	// rate is halved (down to AdaptiveMinTPS) when more than this share of
+	// them failed, and otherwise raised by AdaptiveStepTPS back up to the
+	// pace set by WaitMilis, which must be > 0. RateAdjustments counts the
+	// changes, guarded by Mu.
+	AdaptiveErrorRate float64
+	AdaptiveWindow    int
+	AdaptiveMinTPS    float64
+	AdaptiveStepTPS   float64
+	RateAdjustments   int
+	adaptiveMaxTPS    float64
+	adaptiveTPS       float64
+	adaptiveSent      int
+	adaptiveFailed    int
+
+	// Metrics, when set, receives the sent, success and failed counters and
+	// the broadcast latency of every broadcast.
+	Metrics *metrics.Statsd
+
+	// LatencyBuckets are the upper bounds of the histogram of broadcast
+	// latencies kept for the summary and report (DefaultLatencyBuckets when
+	// empty); LatencyCSV, when set, also writes its buckets to that file.
+	LatencyBuckets []time.Duration
+	LatencyCSV     string
+	latency        *latencyHistogram
+
+	// BroadcastToAll sends every transaction to all endpoints of RpcUrl at once
+	// instead of round-robin, logging which endpoints accepted it.
+	BroadcastToAll bool
+
+	// TypedData, when set, switches the run to EIP-712 signing: each wallet signs
+	// it once per transaction of its batch and the throughput is logged. With a
+	// Verifier, the transactions are then sent to that contract, each carrying
+	// the wallet's signature as verify(bytes32,bytes) calldata; without one,
+	// nothing is broadcast. Signed counts the signatures produced, guarded by Mu.
+	TypedData *apitypes.TypedData
+	Verifier  *common.Address
+	Signed    int
+
+	// Retries is how many more times a broadcast is attempted, RetryDelay apart,
+	// when Retryable reports its error as transient (nil: ethwallet.DefaultRetryablePatterns).
+	// Retried counts the extra attempts, guarded by Mu.
+	Retries    int
+	RetryDelay time.Duration
+	Retryable  func(error) bool
+	Retried    int
+
+	// NonceRetries is how many times a broadcast rejected for a nonce too low
+	// is re-signed with the wallet's nonce resynced from the chain and sent
+	// again. NonceResyncs counts those re-signs, guarded by Mu.
+	NonceRetries   int
+	NonceResyncs   int
+	resyncedNonces map[int]uint64
+	// CaptureErrors keeps the wallet, nonce, hash and error of the first this
+	// many failed broadcasts; they are printed with the summary and added to the report.
+	CaptureErrors  int
+	capturedErrors []ErrorRecord
+
+	// RetryJitter randomizes each RetryDelay from Seed (see JitterMode); "" means none.
+	RetryJitter JitterMode
+	jitterRng   *rand.Rand
+
+	// BroadcastOrder sets the order transactions are broadcast in (see
+	// BroadcastOrder); "" keeps them grouped by wallet.
+	BroadcastOrder BroadcastOrder
+
+	// Signer selects the transaction type and signer (see ethwallet.Signer);
+	// "" signs EIP-1559 transactions with the London signer.
+	Signer ethwallet.Signer
+
+	// DumpRawTxs, when set, writes every signed transaction to this file as
+	// RLP hex and ends the run without broadcasting anything.
+	DumpRawTxs string
+
+	// Warmup sends one transaction per wallet before the rest and aborts the run
+	// unless the node accepts every one of them. Their outcomes are kept apart
+	// from the main counters in WarmupAccepted and WarmupFailed, guarded by Mu.
+	Warmup         bool
+	WarmupAccepted int
+	WarmupFailed   int
+
+	// LogWorkerID adds the wallet index to every record logged while building
+	// or broadcasting that wallet's transactions.
+	LogWorkerID bool
+
+	// AutoWallets derives the wallets of Mnemonic one by one, up to
+	// AutoWalletsScan indexes (DefaultAutoWalletsScan when 0), and sends only
+	// from those that hold funds, until their balances cover TxNumber
+	// transactions; WalletsNumber is then set to the funded wallets found.
+	AutoWallets     bool
+	AutoWalletsScan int
+
+	// Interrupt, when closed or sent to, stops the run like a timeout: no
+	// further transaction is broadcast and the summary is printed. With
+	// confirmation tracking, receipts of the transactions already broadcast are
+	// still collected for up to ShutdownGrace.
+	Interrupt     <-chan struct{}
+	ShutdownGrace time.Duration
+
+	// AssumeYes skips the confirmation asked before sending on a known mainnet.
+	// Prompt asks it; nil reads the answer from stdin.
+	AssumeYes bool
+	Prompt    Prompter
+
+	// Hooks for programs embedding the manager, to follow each transaction
+	// without parsing logs. They are called from the run's goroutines, possibly
+	// concurrently, and never while Mu is held; each may be nil.
+	// OnSigned receives every transaction signed for broadcast, OnBroadcast the
+	// outcome of every broadcast (err is nil when the node accepted it) and
+	// OnConfirmed the receipt of every mined transaction.
+	OnSigned    func(tx *types.Transaction)
+	OnBroadcast func(hash common.Hash, err error)
+	OnConfirmed func(receipt *types.Receipt)
+	// Events, when set, receives the planned transactions of every wallet and
+	// then the outcome of every broadcast (see Event), for live displays.
+	// Broadcasts wait for the receiver, so it has to keep reading.
+	Events chan<- Event
+
+	// CheckpointPath, when set, receives the progress of the run every
+	// CheckpointInterval and at its end (see Checkpoint). Resume, when set, is
+	// the checkpoint of an earlier run whose accepted transactions are skipped.
+	CheckpointPath     string
+	CheckpointInterval time.Duration
+	Resume             *Checkpoint
+	// SummaryInterval, when > 0, logs a running summary (sent, success,
+	// failed, tx/s) this often while transactions are broadcast.
+	SummaryInterval time.Duration
+	walletSent      map[int]int
+	walletNonce     map[int]uint64
+
+	// sent holds the hashes of accepted transactions so none is broadcast twice. Guarded by Mu.
+	sent map[common.Hash]struct{}
+
+	// dial, when set, connects the run instead of dialling RpcUrl, e.g. to a
+	// stub backend in tests.
+	dial func(ctx context.Context) (*rpc.RpcPool, error)
+
+	// heads follows the chain head for ConfirmBlockTimeout; Run starts it.
+	heads *headWatcher
+
+	pool             *rpc.RpcPool
+	endpointAccepted map[string]int
+	endpointFirst    map[string]int
+
+	startedAt time.Time
+}
+
+// pendingTx is a signed transaction together with the wallet that signed it
+// and the logger its records go through.
+type pendingTx struct {
+	index  int
+	wallet *ethwallet.WalletInfo
+	tx     *types.Transaction
+	log    *logger.Logger
+	// kind names the transaction's type, tagged when it is built, for the
+	// per-type counts of the summary.
+	kind string
+	// span covers the transaction from build to broadcast and confirmation.
+	span trace.Span
+	// sentHead is the chain head when the transaction was broadcast, which
+	// ConfirmBlockTimeout counts from; nil when it is not followed or not
+	// known yet.
+	sentHead *big.Int
+}
+
+// waitOrTimeout waits for wg, giving up when timeout fires first.
+// It reports whether wg finished. A nil timeout waits forever.
+func waitOrTimeout(wg *sync.WaitGroup, timeout <-chan struct{}) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-timeout:
+		return false
+	}
 }
 
-func (t *TxManager) Run() {
+// ErrNoWallets is returned by Run when there are no wallets to send from.
+var ErrNoWallets = errors.New("no wallets to send from")
+
+// Run derives the wallets, builds every batch and broadcasts it.
+// It returns an error when the run cannot start; broadcast failures are only counted.
+func (t *TxManager) Run() error {
+	t.startedAt = t.clock().Now()
+
+	// runCtx ends when the run timeout expires or the run is aborted (see FailFast
+	// and Interrupt);
+	// context.Cause tells which. Only the timeout stops the waits below: an
+	// aborted run still waits for its in-flight goroutines to wind down.
+	runCtx, abort := context.WithCancelCause(context.Background())
+	defer abort(nil)
+	var timeout <-chan struct{}
+	if t.RunTimeout > 0 {
+		timeoutCtx, cancelTimeout := t.withTimeout(context.Background(), t.RunTimeout)
+		defer cancelTimeout()
+		timeout = timeoutCtx.Done()
+		context.AfterFunc(timeoutCtx, func() {
+			abort(context.Cause(timeoutCtx))
+		})
+	}
+	confirmCtx, stopConfirm := t.watchInterrupt(runCtx, abort)
+	defer stopConfirm()
+	r := &runState{ctx: runCtx, abort: abort, confirmCtx: confirmCtx, timeout: timeout}
+
+	if t.WalletsNumber <= 0 {
+		return fmt.Errorf("%w: wallet count is %d", ErrNoWallets, t.WalletsNumber)
+	}
+	if t.WalletTxCounts != nil {
+		if len(t.WalletTxCounts) != t.WalletsNumber {
+			return fmt.Errorf("%d wallet transaction counts given for %d wallets", len(t.WalletTxCounts), t.WalletsNumber)
+		}
+		t.TxNumber = sumCounts(t.WalletTxCounts)
+	}
+	if t.TxTemplate != nil {
+		t.TxNumber = len(t.TxTemplate) * t.WalletsNumber
+	}
 
-	rpcURL := t.RpcUrl
 	mnemonic := t.Mnemonic
 	batch := t.TxNumber / t.WalletsNumber
 	walletsNumber := t.WalletsNumber
@@ -35,76 +490,646 @@ func (t *TxManager) Run() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	pool, err := t.dialPool(ctx)
 	if err != nil {
-		panic("failed to connect to RPC")
+		return err
 	}
-	defer client.Close()
+	headsCtx, stopHeads := context.WithCancel(context.Background())
+	defer func() {
+		if r.outstanding.Load() == 0 {
+			stopHeads()
+			pool.Close()
+			return
+		}
+		// A timed out phase returned with goroutines still sending through the
+		// pool; closing it now would fail them with "use of closed connection".
+		go func() {
+			r.running.Wait()
+			stopHeads()
+			pool.Close()
+		}()
+	}()
+	t.pool = pool
+	if err := t.weighEndpoints(); err != nil {
+		return err
+	}
+	client := pool.Primary().Client
 
 	chainId, err := client.NetworkID(ctx)
 	if err != nil {
-		fmt.Printf("failed to get chain ID")
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	if chainId == nil {
+		return ethwallet.ErrNilChainID
 	}
+	logNetwork(chainId)
 
-	wallets, _ := ethwallet.DeriveEthereumWalletsFromMnemonic(mnemonic, walletsNumber, client, t.WaitMilis)
+	t.heads = nil
+	if t.ConfirmBlockTimeout > 0 {
+		t.heads = t.watchHeads(headsCtx, client)
+	}
 
-	wg := sync.WaitGroup{}
-	t.Wallets = wallets
-	var txs []*types.Transaction
+	if err := t.confirmMainnet(chainId); err != nil {
+		return err
+	}
 
-	for _, wallet := range wallets {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			balance, err := wallet.GetBalanceEther(rpcURL)
+	if err := t.checkGasPrice(client); err != nil {
+		return err
+	}
+
+	to, err := t.recipient(client)
+	if err != nil {
+		return err
+	}
+
+	batchOpts := ethwallet.BatchOptions{
+		TipPercentile: t.TipPercentile,
+		TipBlocks:     t.TipBlocks,
+		Value:         t.Value,
+		MaxFee:        t.MaxFee,
+		MinTip:        t.MinTip,
+		GasMultiplier: t.GasMultiplier,
+		Oracle:        t.GasOracle,
+		Signer:        t.Signer,
+		To:            to,
+		Data:          t.payload(),
+		Tag:           t.dataTag(0),
+	}
+
+	if t.PrivateKey == "" && t.Derivation != (ethwallet.DerivationPath{}) {
+		logger.Infof("Deriving wallets at %s", t.Derivation)
+	}
+
+	// In streaming mode wallets only holds the wallets taken from the stream so far.
+	// With AutoWallets, autoCounts holds how many transactions each funded wallet sends.
+	var (
+		wallets    []*ethwallet.WalletInfo
+		stream     <-chan *ethwallet.WalletInfo
+		streamErrs <-chan error
+		autoCounts []int
+	)
+	if t.StreamWallets {
+		stream, streamErrs = ethwallet.DeriveWalletsChan(runCtx, mnemonic, t.Derivation, walletsNumber, client, t.WaitMilis)
+		if t.SharedFees {
+			// Shared fees are priced from the first wallet.
+			if first, ok := <-stream; ok {
+				t.labelWallet(0, first)
+				wallets = append(wallets, first)
+			}
+		}
+	} else if t.AutoWallets {
+		if wallets, autoCounts, err = t.discoverWallets(runCtx, client, batchOpts); err != nil {
+			return err
+		}
+		// The run goes on with the funded wallets only.
+		walletsNumber = len(wallets)
+		t.WalletsNumber = walletsNumber
+		for i, wallet := range wallets {
+			t.labelWallet(i, wallet)
+		}
+	} else {
+		var err error
+		wallets, err = t.deriveWallets(client, walletsNumber)
+		if len(wallets) == 0 {
 			if err != nil {
-				logger.Errorf("failed to get balance for address %s: %v", wallet.Address, err)
+				return fmt.Errorf("%w: %v", ErrNoWallets, err)
 			}
+			return ErrNoWallets
+		}
+		for i, wallet := range wallets {
+			t.labelWallet(i, wallet)
+		}
+	}
 
-			fmt.Println(wallet.Address, balance)
+	if t.Faucet != nil {
+		t.prefund(runCtx, client, wallets)
+	}
 
-			tx, err := wallet.SendEIP1559ETHTransferInBatch(chainId, (batch))
-			if err != nil {
-				logger.Errorf("failed to send transaction: %v", err)
+	counts := make([]int, walletsNumber)
+	if autoCounts != nil {
+		counts = autoCounts
+	} else if t.WalletTxCounts != nil {
+		copy(counts, t.WalletTxCounts)
+	} else if t.SplitWeights != nil {
+		counts = splitTransactions(t.TxNumber, t.SplitWeights)
+	} else {
+		for i := range counts {
+			counts[i] = batch
+		}
+	}
+
+	if t.Resume != nil {
+		if err := t.resume(t.Resume, counts); err != nil {
+			return err
+		}
+	}
+
+	t.initWalletLimiters(walletsNumber)
+	t.initRetryJitter()
+	t.initInflight()
+	t.initAdaptive()
+
+	// walletOpts holds the options each wallet builds its batch with; they only
+	// differ in typed-data mode, where every wallet sends its own signature.
+	walletOpts := make([]ethwallet.BatchOptions, walletsNumber)
+	for i := range walletOpts {
+		walletOpts[i] = batchOpts
+	}
+
+	if t.TypedData != nil {
+		sigs, err := t.signTypedData(wallets, counts)
+		if err != nil {
+			return fmt.Errorf("failed to sign typed data: %w", err)
+		}
+		if t.Verifier == nil {
+			return t.finish()
+		}
+		for i, sig := range sigs {
+			if walletOpts[i], err = t.verifierOptions(batchOpts, sig); err != nil {
+				return err
 			}
-			t.Mu.Lock()
-			defer t.Mu.Unlock()
+		}
+		// Signatures have the same length, so wallet 0's call prices them all.
+		if len(wallets) > 0 {
+			batchOpts = walletOpts[0]
+		}
+	}
 
-			txs = append(txs, tx...)
-		}()
+	if t.ReplayFees != nil {
+		if err := t.replayFees(walletOpts); err != nil {
+			return err
+		}
+		if len(walletOpts) > 0 {
+			batchOpts.Fees = walletOpts[0].Fees
+		}
+		logger.Infof("Replaying the fees of a previous run for %d wallets", len(walletOpts))
+	} else if t.SharedFees && len(wallets) > 0 {
+		// The dial timeout above may have run out during the setup since.
+		feeCtx, cancelFees := context.WithTimeout(context.Background(), 30*time.Second)
+		fees, err := wallets[0].FetchFeeData(feeCtx, batchOpts)
+		cancelFees()
+		if err != nil {
+			return fmt.Errorf("failed to fetch shared fee data: %w", err)
+		}
+		logger.Infof("Shared fees: gas limit %d, base fee %s, tip %s, max fee %s", fees.GasLimit, fees.BaseFee, fees.TipCap, fees.MaxFeeCap)
+		batchOpts.Fees = fees
+		for i := range walletOpts {
+			walletOpts[i].Fees = fees
+		}
+	}
+
+	for i, override := range t.FeeOverrides {
+		if i < len(walletOpts) {
+			walletOpts[i].Override = &override
+		}
+	}
+
+	if t.RequireFunded {
+		if err := t.checkFunding(client, wallets, walletOpts); err != nil {
+			return err
+		}
 	}
 
-	wg.Wait()
+	if !t.StreamWallets {
+		t.logBalances(client, wallets)
+	}
+	if t.ValueDist != nil {
+		t.resolveSeed()
+		logger.Infof("Drawing values from %s", t.ValueDist)
+	}
+	for i := range walletOpts {
+		walletOpts[i].Log = t.workerLog(i)
+		walletOpts[i].Tag = t.dataTag(i)
+		walletOpts[i].Sequence = t.TxTemplate
+		if t.ValueDist != nil {
+			walletOpts[i].Values = t.ValueDist.NewSampler(t.Seed, uint64(i))
+		}
+		walletOpts[i].OnFees = func(fees *ethwallet.FeeData) { t.recordFees(i, fees) }
+	}
 
-	wg = sync.WaitGroup{}
+	t.syncNonces(client, wallets, walletOpts)
+
+	r.client, r.chainID = client, chainId
+	r.wallets, r.stream, r.streamErrs = wallets, stream, streamErrs
+	r.counts, r.walletOpts = counts, walletOpts
+	txs, err := t.buildTransactions(r)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return t.finish()
+	}
 
-	wg.Add(len(txs))
 	logger.Infof("Transaction sent successfully: %d", len(txs))
 
-	for _, tx := range txs {
-		go func() {
+	if t.ShowFees {
+		t.logFeeTable()
+	}
 
-			defer wg.Done()
+	if t.DumpRawTxs != "" {
+		if err := dumpRawTxs(t.DumpRawTxs, txs); err != nil {
+			dropSpans(txs, "failed to write raw transactions")
+			return err
+		}
+		logger.Infof("Wrote %d signed transactions to %s without broadcasting", len(txs), t.DumpRawTxs)
+		dropSpans(txs, "written to "+t.DumpRawTxs)
+		return nil
+	}
 
-			err := client.SendTransaction(context.Background(), tx)
-			t.Mu.Lock()
+	total := len(txs)
+	if t.Warmup {
+		rest, err := t.warmup(runCtx, txs)
+		if err != nil {
+			t.finish()
+			return err
+		}
+		txs = rest
+	}
+	txs = t.orderBroadcasts(txs)
+
+	if t.CheckpointPath != "" && t.CheckpointInterval > 0 {
+		checkpointCtx, stopCheckpoints := context.WithCancel(runCtx)
+		defer stopCheckpoints()
+		go t.checkpointLoop(checkpointCtx)
+	}
+
+	t.emitStart(runCtx, txs, len(t.Wallets))
+	stopSummary := func() {}
+	if t.SummaryInterval > 0 {
+		stopSummary = t.startSummary(runCtx, total)
+	}
+	stopNonceWatch := func() {}
+	if t.NonceStallTimeout > 0 {
+		stopNonceWatch = t.startNonceWatch(confirmCtx, client)
+	}
+
+	t.broadcastTransactions(r, txs)
+	stopSummary()
+	stopNonceWatch()
+	t.emit(context.Background(), Event{Kind: EventDone})
+
+	return t.finish()
+}
+
+// runState is what the phases of a run share: the contexts and timeout that
+// bound it, the goroutines it has in flight, and the wallets and options the
+// setup of Run prepared for the build phase. Tests can fill one in by hand
+// to drive buildTransactions or broadcastTransactions on their own.
+type runState struct {
+	// ctx ends when the run times out or is aborted, through abort;
+	// confirmCtx outlives an interrupt so confirmations can still finish.
+	ctx        context.Context
+	abort      context.CancelCauseFunc
+	confirmCtx context.Context
+	// timeout fires at the run timeout; nil waits forever.
+	timeout <-chan struct{}
+
+	// outstanding counts goroutines that have been started but not finished yet,
+	// so a timed out phase can report what it is leaving behind; running waits
+	// for the same goroutines, so the pool is not closed underneath them.
+	outstanding atomic.Int64
+	running     sync.WaitGroup
+
+	client  rpc.EthBackend
+	chainID *big.Int
+	// wallets are the wallets derived up front; in streaming mode the rest
+	// arrive on stream, which reports how derivation ended on streamErrs.
+	wallets    []*ethwallet.WalletInfo
+	stream     <-chan *ethwallet.WalletInfo
+	streamErrs <-chan error
+	// counts and walletOpts hold how many transactions each wallet builds
+	// and the options it builds them with.
+	counts     []int
+	walletOpts []ethwallet.BatchOptions
+}
+
+// buildTransactions signs the batch of every wallet of r, concurrently, and
+// returns the transactions in the order they were built, each wallet's in
+// nonce order. It sets Wallets to every wallet built from, streamed ones
+// included. It only fails when the run timeout fires first.
+func (t *TxManager) buildTransactions(r *runState) ([]pendingTx, error) {
+	// Once the build times out, txs is given up on: transactions built
+	// after that are dropped as they come in. Both are guarded by Mu.
+	var (
+		wg       sync.WaitGroup
+		txs      []pendingTx
+		timedOut bool
+	)
+
+	build := func(i int, wallet *ethwallet.WalletInfo) {
+		wg.Add(1)
+		r.running.Add(1)
+		r.outstanding.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.running.Done()
+			defer r.outstanding.Add(-1)
+			buildStart := t.clock().Now()
+			tx, err := wallet.SendEIP1559ETHTransferInBatch(r.chainID, r.counts[i], r.walletOpts[i])
+			built := t.clock().Now()
 			if err != nil {
-				t.Failed++
-				logger.Errorf("%d/%d failed to send transaction: %v", t.Failed, t.Failed+t.Success, err)
-			} else {
-				t.Success++
-				logger.Debugf("%d/%d Transaction sent successfully: %v", t.Success, t.Failed+t.Success, tx.Hash())
+				r.walletOpts[i].Log.Errorf("wallet %s: %v", wallet.Name(), err)
+			}
+			if t.Simulate {
+				tx = t.simulateBatch(r.ctx, r.client, i, wallet, r.walletOpts[i].Log, tx)
+			}
+			t.Mu.Lock()
+			var (
+				kept   []*types.Transaction
+				capped bool
+			)
+			for _, signed := range tx {
+				p := pendingTx{index: i, wallet: wallet, tx: signed, log: r.walletOpts[i].Log, kind: txTypeName(signed.Type())}
+				var spanCtx context.Context
+				spanCtx, p.span = tracer.Start(context.Background(), "transaction", trace.WithTimestamp(buildStart), txAttributes(p))
+				_, build := tracer.Start(spanCtx, "build", trace.WithTimestamp(buildStart))
+				build.End(trace.WithTimestamp(built))
+
+				switch {
+				case timedOut:
+					dropSpans([]pendingTx{p}, "run timeout")
+				case capped || !t.reserveSpend(signed):
+					// Later nonces of this wallet could never be mined without this one.
+					capped = true
+					dropSpans([]pendingTx{p}, "spend cap reached")
+				default:
+					kept = append(kept, signed)
+					txs = append(txs, p)
+				}
 			}
 			t.Mu.Unlock()
+
+			if t.OnSigned != nil {
+				for _, signed := range kept {
+					t.OnSigned(signed)
+				}
+			}
 		}()
-		time.Sleep(time.Duration(t.WaitMilis) * time.Millisecond)
 	}
 
-	wg.Wait()
+	wallets := r.wallets
+	for i, wallet := range wallets {
+		build(i, wallet)
+	}
+	if r.stream != nil {
+		for wallet := range r.stream {
+			t.labelWallet(len(wallets), wallet)
+			wallets = append(wallets, wallet)
+			build(len(wallets)-1, wallet)
+		}
+		if err := <-r.streamErrs; err != nil {
+			logger.Errorf("wallet derivation stopped after %d wallets: %v", len(wallets), err)
+		}
+	}
+	t.Wallets = wallets
+
+	if !waitOrTimeout(&wg, r.timeout) {
+		t.Mu.Lock()
+		timedOut = true
+		dropSpans(txs, "run timeout")
+		t.Mu.Unlock()
+		return nil, fmt.Errorf("run timeout of %v reached while building transactions, %d wallet goroutines outstanding", t.RunTimeout, r.outstanding.Load())
+	}
+	return txs, nil
+}
+
+// broadcastTransactions sends txs in order, within the inflight limit and the
+// dispatch rate, and waits for their broadcasts and confirmations to settle.
+// It stops dispatching once the run ends and stops waiting at the run
+// timeout. It returns the report of the run so far.
+func (t *TxManager) broadcastTransactions(r *runState, txs []pendingTx) RunReport {
+	var wg sync.WaitGroup
+	for i, p := range txs {
+		if r.ctx.Err() != nil || t.acquireInflight(r.ctx) != nil {
+			logger.Errorf("%s while dispatching transactions", t.stopReason(r.ctx))
+			dropSpans(txs[i:], t.stopReason(r.ctx))
+			break
+		}
+
+		wg.Add(1)
+		r.running.Add(1)
+		r.outstanding.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.running.Done()
+			defer r.outstanding.Add(-1)
+			defer t.releaseInflight()
+
+			t.broadcast(r.ctx, r.confirmCtx, r.abort, r.client, p)
+		}()
+		if delay := t.dispatchDelay(); delay > 0 {
+			t.clock().Sleep(delay)
+		}
+	}
+
+	if !waitOrTimeout(&wg, r.timeout) {
+		logger.Errorf("run timeout of %v reached while broadcasting, %d broadcast goroutines outstanding", t.RunTimeout, r.outstanding.Load())
+	}
+	return t.Report()
+}
+
+// logNetwork reports the chain the run targets, warning when it is a production network.
+func logNetwork(chainID *big.Int) {
+	logger.Infof("Connected to chain ID %s (%s)", chainID, rpc.ChainName(chainID))
+	if rpc.IsMainnet(chainID) {
+		logger.Warnf("===================== MAINNET =====================")
+		logger.Warnf("chain ID %s is %s: every transaction spends real funds", chainID, rpc.ChainName(chainID))
+		logger.Warnf("===================================================")
+	}
+}
 
+// dialPool connects to every endpoint of RpcUrl.
+func (t *TxManager) dialPool(ctx context.Context) (*rpc.RpcPool, error) {
+	if t.dial != nil {
+		return t.dial(ctx)
+	}
+	return rpc.DialPool(ctx, rpc.SplitURLs(t.RpcUrl), rpc.DialOptions{
+		ClientName: t.RpcClientName,
+		Trace:      t.RpcTrace,
+		Endpoints:  t.RpcEndpoints,
+	})
+}
+
+// deriveWallets returns the first count wallets of Mnemonic, or the single
+// wallet of PrivateKey when it is set.
+func (t *TxManager) deriveWallets(client rpc.EthBackend, count int) ([]*ethwallet.WalletInfo, error) {
+	if t.PrivateKey == "" {
+		return ethwallet.DeriveEthereumWalletsFromMnemonic(t.Mnemonic, t.Derivation, count, client, t.WaitMilis)
+	}
+	if count != 1 {
+		return nil, fmt.Errorf("a private key gives a single wallet, %d requested", count)
+	}
+	wallet, err := ethwallet.WalletFromHexKey(t.PrivateKey, client, t.WaitMilis)
+	if err != nil {
+		return nil, err
+	}
+	return []*ethwallet.WalletInfo{wallet}, nil
+}
+
+// recipient resolves To through client; nil means every wallet sends to itself.
+func (t *TxManager) recipient(client rpc.EthBackend) (*common.Address, error) {
+	if t.To == "" {
+		return nil, nil
+	}
+	address, err := rpc.ResolveAddress(client, t.To)
+	if err != nil {
+		return nil, err
+	}
+	if rpc.IsENSName(t.To) {
+		logger.Infof("Sending to %s (%s)", t.To, address)
+	}
+	return &address, nil
+}
+
+// labelWallet gives wallet index its label from WalletLabels, if it has one.
+func (t *TxManager) labelWallet(index int, wallet *ethwallet.WalletInfo) {
+	if label, ok := t.WalletLabels[index]; ok {
+		wallet.Label = label
+	}
+}
+
+// workerLog returns the logger for the goroutines of wallet index: with
+// LogWorkerID it tags records with the index, otherwise it is the plain logger.
+func (t *TxManager) workerLog(index int) *logger.Logger {
+	if !t.LogWorkerID {
+		return nil
+	}
+	return logger.With("wallet", index)
+}
+
+// stopReason explains why the run context ended.
+func (t *TxManager) stopReason(runCtx context.Context) string {
+	cause := context.Cause(runCtx)
+	if errors.Is(cause, context.DeadlineExceeded) {
+		return fmt.Sprintf("run timeout of %v reached", t.RunTimeout)
+	}
+	return fmt.Sprintf("run aborted (%v)", cause)
+}
+
+// finish prints the summary and writes the final checkpoint and the run report,
+// if they were requested.
+func (t *TxManager) finish() error {
+	t.logSummary()
+
+	if t.CheckpointPath != "" {
+		if err := t.writeCheckpoint(); err != nil {
+			return err
+		}
+		logger.Infof("Checkpoint written to %s", t.CheckpointPath)
+	}
+
+	if t.LatencyCSV != "" {
+		t.Mu.Lock()
+		latency := t.latencySnapshot()
+		t.Mu.Unlock()
+		if latency != nil {
+			if err := writeLatencyCSV(t.LatencyCSV, latency); err != nil {
+				return err
+			}
+			logger.Infof("Latency histogram written to %s", t.LatencyCSV)
+		}
+	}
+
+	if t.ReportPath == "" {
+		return nil
+	}
+	if err := t.writeReport(); err != nil {
+		return err
+	}
+	logger.Infof("Run report written to %s", t.ReportPath)
+	return nil
+}
+
+// logSummary prints the success and failure counts gathered so far.
+func (t *TxManager) logSummary() {
+	t.Mu.Lock()
 	success := t.Success
 	failed := t.Failed
+	drained := t.Drained
+	cancelled := t.Cancelled
+	failFastErr := t.failFastErr
+	breakerErr := t.breakerErr
+	confirmed, reverted, unconfirmed := t.Confirmed, t.Reverted, t.Unconfirmed
+	escalated, escalations := t.Escalated, t.Escalations
+	nonceStalls := t.NonceStalls
+	retried, nonceResyncs := t.Retried, t.NonceResyncs
+	rateAdjustments, adaptiveTPS := t.RateAdjustments, t.adaptiveTPS
+	inMempool, vanished := t.InMempool, t.Vanished
+	warmupAccepted, warmupFailed := t.WarmupAccepted, t.WarmupFailed
+	captured := t.capturedErrors
+	gasUsage := maps.Clone(t.gasUsage)
+	reverts := slices.Clone(t.reverts)
+	simulationReverts := slices.Clone(t.simulationReverts)
+	latency := t.latencySnapshot()
+	typeCounts := maps.Clone(t.typeCounts)
+	walletCounts := t.walletCounts()
+	spent := t.Spent
+	if spent == nil {
+		spent = new(big.Int)
+	}
+	t.Mu.Unlock()
+
+	if t.TypedData != nil && t.Verifier == nil {
+		// Signing-only run: there is nothing else to report.
+		return
+	}
 
+	if t.Warmup {
+		logger.Infof("Warm-up: %d accepted, %d failed", warmupAccepted, warmupFailed)
+	}
 	logger.Infof("Total Success Count: %d/%d", success, success+failed)
 	logger.Infof("Total Failed Count: %d/%d", failed, success+failed)
+	if len(typeCounts) > 0 {
+		logger.Infof("By transaction type:")
+		logTypeCounts(typeCounts)
+	}
+	if walletCounts != nil {
+		logger.Infof("By wallet:")
+		t.logWalletCounts(walletCounts)
+	}
+	if cancelled > 0 {
+		logger.Infof("Cancelled by the end of the run: %d", cancelled)
+	}
+	if failFastErr != nil {
+		logger.Errorf("Stopped early by -fail-fast: %v", failFastErr)
+	}
+	if breakerErr != nil {
+		logger.Errorf("Stopped early: %v", breakerErr)
+	}
+	if t.ExpectDrain {
+		logger.Infof("Drained (insufficient funds, expected): %d", drained)
+	}
+	if latency != nil {
+		logLatency(latency)
+	}
+	if t.Simulate {
+		logger.Infof("Simulation: %d transactions skipped because they would revert", len(simulationReverts))
+		logRevertReasons(simulationReverts)
+	}
+	if t.WaitReceipts || t.EscalateAfter > 0 {
+		logger.Infof("Confirmed: %d/%d (reverted: %d, unconfirmed: %d)", confirmed, success, reverted, unconfirmed)
+		logRevertReasons(reverts)
+		logGasUsage(gasUsage)
+	}
+	if t.VerifyMempool {
+		logger.Infof("Mempool check: %d/%d found by the node, %d accepted but vanished", inMempool, success, vanished)
+	}
+	if t.MaxSpend != nil {
+		logger.Infof("Spent (worst case): %s of the %s ether cap", ethwallet.FormatEther(spent), ethwallet.FormatEther(t.MaxSpend))
+	}
+	t.logEndpointSummary()
+	if t.Retries > 0 {
+		logger.Infof("Retried broadcasts: %d", retried)
+	}
+	if t.NonceRetries > 0 {
+		logger.Infof("Nonce resyncs: %d transactions re-signed after a nonce too low", nonceResyncs)
+	}
+	if adaptiveTPS > 0 {
+		logger.Infof("Adaptive throttle: %d rate adjustments, ending at %.1f tx/s", rateAdjustments, adaptiveTPS)
+	}
+	if t.NonceStallTimeout > 0 {
+		logger.Infof("Nonce stalls: %d wallets stopped advancing for at least %v", nonceStalls, t.NonceStallTimeout)
+	}
+	if t.EscalateAfter > 0 {
+		logger.Infof("Escalated: %d/%d transactions needed a fee bump (%d bumps in total)", escalated, success, escalations)
+	}
+	logCapturedErrors(captured)
 }
@@ -0,0 +1,84 @@
+package txmanager
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/rpc"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// testMnemonic is the well-known development mnemonic; testKey is its first account.
+const (
+	testMnemonic = "test test test test test test test test test test test junk"
+	testKey      = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+)
+
+// testWallet returns the wallet of testKey sending through client.
+func testWallet(t testing.TB, client rpc.EthBackend) *ethwallet.WalletInfo {
+	t.Helper()
+	wallet, err := ethwallet.WalletFromHexKey(testKey, client, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wallet
+}
+
+// nullPool returns a pool with one NullBackend endpoint.
+func nullPool(t testing.TB) *rpc.RpcPool {
+	t.Helper()
+	pool, err := rpc.DialPool(context.Background(), []string{rpc.NullURL}, rpc.DialOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// testFees prices transactions at a 1 gwei tip under a 3 gwei fee cap.
+var testFees = &ethwallet.FeeData{
+	GasLimit:  21000,
+	BaseFee:   big.NewInt(1e9),
+	TipCap:    big.NewInt(1e9),
+	MaxFeeCap: big.NewInt(3e9),
+}
+
+// slowBackend is a NullBackend whose broadcasts take delay, standing in for
+// the round trip to a real node.
+type slowBackend struct {
+	*rpc.NullBackend
+	delay time.Duration
+}
+
+func (b *slowBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	select {
+	case <-time.After(b.delay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return b.NullBackend.SendTransaction(ctx, tx)
+}
+
+// poolOf returns a pool with client as its only endpoint.
+func poolOf(client rpc.EthBackend) *rpc.RpcPool {
+	return rpc.NewPool(&rpc.Endpoint{URL: "stub", Client: client})
+}
+
+// signedTxs signs n self-transfers from wallet, nonces 0 to n-1, ready to
+// broadcast.
+func signedTxs(t testing.TB, wallet *ethwallet.WalletInfo, n int) []pendingTx {
+	t.Helper()
+	txs := make([]pendingTx, n)
+	for i := range txs {
+		tx, err := wallet.SignTransaction("", rpc.NullChainID, uint64(i), testFees, &wallet.Address, big.NewInt(1), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs[i] = pendingTx{wallet: wallet, tx: tx, kind: txTypeName(tx.Type()), span: trace.SpanFromContext(context.Background())}
+	}
+	return txs
+}
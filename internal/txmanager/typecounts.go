@@ -0,0 +1,37 @@
+package txmanager
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// TypeCount is how many broadcasts of one transaction type the node
+// accepted and rejected.
+type TypeCount struct {
+	Success int `json:"success"`
+	Failed  int `json:"failed"`
+}
+
+// countType adds a broadcast outcome to the counts of kind. Callers must hold Mu.
+func (t *TxManager) countType(kind string, failed bool) {
+	if t.typeCounts == nil {
+		t.typeCounts = make(map[string]TypeCount)
+	}
+	count := t.typeCounts[kind]
+	if failed {
+		count.Failed++
+	} else {
+		count.Success++
+	}
+	t.typeCounts[kind] = count
+}
+
+// logTypeCounts prints the broadcast outcomes per transaction type.
+func logTypeCounts(counts map[string]TypeCount) {
+	for _, kind := range slices.Sorted(maps.Keys(counts)) {
+		c := counts[kind]
+		logger.Infof("  %-12s success %d, failed %d", kind, c.Success, c.Failed)
+	}
+}
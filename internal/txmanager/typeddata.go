@@ -0,0 +1,66 @@
+package txmanager
+
+import (
+	"fmt"
+	"sync"
+
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// signTypedData has every wallet sign TypedData counts[i] times concurrently and
+// logs the signing throughput. It returns the last signature of each wallet, by index.
+func (t *TxManager) signTypedData(wallets []*ethwallet.WalletInfo, counts []int) ([][]byte, error) {
+	sigs := make([][]byte, len(wallets))
+	errs := make([]error, len(wallets))
+
+	start := t.clock().Now()
+	var wg sync.WaitGroup
+	for i, wallet := range wallets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Sign at least once so every wallet has a signature to send.
+			for n := 0; n < max(counts[i], 1); n++ {
+				sig, err := wallet.SignTypedData(*t.TypedData)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				sigs[i] = sig
+				t.Mu.Lock()
+				t.Signed++
+				t.Mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := t.clock().Now().Sub(start)
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("wallet %s: %w", wallets[i].Name(), err)
+		}
+	}
+
+	t.Mu.Lock()
+	signed := t.Signed
+	t.Mu.Unlock()
+	if elapsed > 0 {
+		logger.Infof("Signed %d typed-data payloads in %v (%.0f signatures/s)", signed, elapsed, float64(signed)/elapsed.Seconds())
+	} else {
+		logger.Infof("Signed %d typed-data payloads", signed)
+	}
+	return sigs, nil
+}
+
+// verifierOptions returns a copy of opts that sends sig to the Verifier contract.
+func (t *TxManager) verifierOptions(opts ethwallet.BatchOptions, sig []byte) (ethwallet.BatchOptions, error) {
+	data, err := ethwallet.VerifierCalldata(*t.TypedData, sig)
+	if err != nil {
+		return opts, err
+	}
+	opts.To = t.Verifier
+	opts.Data = data
+	return opts, nil
+}
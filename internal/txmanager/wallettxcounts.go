@@ -0,0 +1,72 @@
+package txmanager
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mdtosif/icarus/internal/logger"
+)
+
+// ParseWalletTxCounts parses a comma-separated list of per-wallet transaction
+// counts such as "100,50,200", one per wallet in index order.
+func ParseWalletTxCounts(spec string) ([]int, error) {
+	var counts []int
+	for _, field := range strings.Split(spec, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid wallet transaction count %q", field)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("wallet transaction count %d must be >= 0", n)
+		}
+		counts = append(counts, n)
+	}
+	return counts, nil
+}
+
+// WalletCount compares the transactions a wallet was given by WalletTxCounts
+// with how many of them the node accepted.
+type WalletCount struct {
+	Intended int `json:"intended"`
+	Sent     int `json:"sent"`
+}
+
+// sumCounts returns the total of counts.
+func sumCounts(counts []int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// walletCounts returns the intended and accepted transactions of every
+// wallet, nil unless WalletTxCounts is set. Callers must hold Mu.
+func (t *TxManager) walletCounts() map[int]WalletCount {
+	if t.WalletTxCounts == nil {
+		return nil
+	}
+	counts := make(map[int]WalletCount, len(t.WalletTxCounts))
+	for i, n := range t.WalletTxCounts {
+		counts[i] = WalletCount{Intended: n, Sent: t.walletSent[i]}
+	}
+	return counts
+}
+
+// logWalletCounts prints the intended and accepted transactions of every
+// wallet, flagging those that fell short.
+func (t *TxManager) logWalletCounts(counts map[int]WalletCount) {
+	for i := range len(counts) {
+		c := counts[i]
+		name := fmt.Sprintf("wallet %d", i)
+		if i < len(t.Wallets) {
+			name = t.Wallets[i].Name()
+		}
+		if c.Sent < c.Intended {
+			logger.Warnf("  %s: sent %d of %d", name, c.Sent, c.Intended)
+		} else {
+			logger.Infof("  %s: sent %d of %d", name, c.Sent, c.Intended)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package txmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// warmup broadcasts the first transaction of every wallet and waits for the
+// node to accept them all before the main run starts. It returns the
+// transactions left for the main run, or an error listing every rejection.
+// Warm-up outcomes are counted in WarmupAccepted and WarmupFailed only.
+func (t *TxManager) warmup(ctx context.Context, txs []pendingTx) ([]pendingTx, error) {
+	var first, rest []pendingTx
+	seen := make(map[common.Address]bool)
+	for _, p := range txs {
+		if seen[p.wallet.Address] {
+			rest = append(rest, p)
+			continue
+		}
+		seen[p.wallet.Address] = true
+		first = append(first, p)
+	}
+
+	logger.Infof("Warm-up: sending 1 transaction from each of %d wallets", len(first))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		rejected []string
+	)
+	for _, p := range first {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sendCtx, span := tracer.Start(trace.ContextWithSpan(ctx, p.span), "warmup")
+			err := ethwallet.ClassifySendError(t.sendWithRetry(sendCtx, p))
+			endSpan(span, err)
+			endSpan(p.span, err)
+
+			t.Mu.Lock()
+			if err != nil {
+				t.WarmupFailed++
+			} else {
+				t.WarmupAccepted++
+				t.markSent(p.tx.Hash())
+				t.recordProgress(p.index, p.tx.Nonce())
+				t.recordSpend(p.tx)
+			}
+			t.Mu.Unlock()
+			if t.OnBroadcast != nil {
+				t.OnBroadcast(p.tx.Hash(), err)
+			}
+
+			if err != nil {
+				mu.Lock()
+				rejected = append(rejected, fmt.Sprintf("%s (nonce %d): %v", p.wallet.Name(), p.tx.Nonce(), err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(rejected) > 0 {
+		dropSpans(rest, "warm-up failed")
+		return nil, fmt.Errorf("warm-up failed for %d/%d wallets:\n  %s", len(rejected), len(first), strings.Join(rejected, "\n  "))
+	}
+
+	logger.Infof("Warm-up passed: %d/%d transactions accepted", len(first), len(first))
+	return rest, nil
+}
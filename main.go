@@ -3,46 +3,113 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
 	"os"
-	"sync"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
 	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/internal/metrics"
+	"github.com/mdtosif/icarus/internal/rpc"
 	"github.com/mdtosif/icarus/internal/txmanager"
+	"github.com/mdtosif/icarus/pkg/icarus"
+	"golang.org/x/term"
 )
 
+const defaultLogLevel = logger.INFO
+
+// Values of -mode.
 const (
-	defaultWallets  = 10
-	defaultWaitTime = 10 * time.Millisecond
-	defaultTxCount  = 100
-	defaultLogLevel = logger.INFO
+	modeRun     = "run"
+	modePending = "pending"
 )
 
 // main is the entry point of the application.
 // It parses command line flags, initializes the transaction manager, and starts the transaction processing.
 func main() {
-	// Command line flags
+	// Command line flags, defaulting to the library's configuration.
+	defaults := icarus.DefaultConfig()
 	mnemonic := flag.String(
 		"mnemonic",
 		"",
-		"BIP-39 mnemonic phrase (required)",
+		"BIP-39 mnemonic phrase (required unless -private-key is given); - reads it from stdin, without echo on a terminal",
+	)
+	privateKey := flag.String(
+		"private-key",
+		"",
+		"Hex private key (with or without 0x) of a single wallet to send from instead of deriving wallets from -mnemonic",
+	)
+	accountIndex := flag.Uint(
+		"account-index",
+		0,
+		"Account level of the derivation path m/44'/60'/{account}'/{change}/{i} of the -mnemonic wallets (hardened); other values give other addresses",
+	)
+	changeIndex := flag.Uint(
+		"change-index",
+		0,
+		"Change level of the derivation path m/44'/60'/{account}'/{change}/{i} of the -mnemonic wallets",
 	)
 	wallets := flag.Int(
 		"wallets",
-		defaultWallets,
+		defaults.WalletsNumber,
 		"Number of wallet instances to create and manage",
 	)
 	wait := flag.Duration(
 		"wait",
-		defaultWaitTime,
-		"Duration to wait between operations (e.g., 10ms, 1s)",
+		defaults.Wait,
+		"Duration to wait between operations (e.g., 10ms, 1s); 0 disables the pause",
+	)
+	perWalletTPS := flag.Float64(
+		"per-wallet-tps",
+		0,
+		"Cap on the transactions per second each wallet sends, on top of the global -wait pacing (each wallet gets min of the two); 0 disables it",
+	)
+	noWait := flag.Bool(
+		"no-wait",
+		false,
+		"Launch broadcasts back to back, ignoring -wait, so throughput is bounded only by the RPC",
 	)
 	rpcURL := flag.String(
 		"rpc-url",
 		"",
-		"Ethereum RPC URL (required)",
+		"Ethereum RPC URL (required); a comma-separated list spreads broadcasts over several endpoints",
+	)
+	rpcClientName := flag.String(
+		"rpc-client-name",
+		"",
+		"User-Agent sent with every RPC request to identify this tool to the provider, e.g. icarus",
+	)
+	rpcConfig := flag.String(
+		"rpc-config",
+		"",
+		`JSON file of per-endpoint settings keyed by RPC URL, e.g. {"https://a": {"timeout": "5s", "user_agent": "icarus", "headers": {"X-Key": "..."}}}`,
+	)
+	rpcWeights := flag.String(
+		"rpc-weights",
+		"",
+		"Comma-separated weight of each -rpc-url endpoint, e.g. 70,30: broadcasts pick endpoints at random in proportion, drawn from -seed, instead of in turn",
+	)
+	rpcTrace := flag.Bool(
+		"rpc-trace",
+		false,
+		"Log the method and id of every outgoing JSON-RPC request (HTTP endpoints, needs -log-level 0)",
 	)
 	logLevel := flag.Int(
 		"log-level",
@@ -51,40 +118,1150 @@ func main() {
 	)
 	txCount := flag.Int(
 		"txns",
-		defaultTxCount,
+		defaults.TxNumber,
 		"Number of transactions to send per wallet",
 	)
+	streamWallets := flag.Bool(
+		"stream-wallets",
+		false,
+		"Derive wallets while earlier ones already build transactions, for very large -wallets (skips the balance listing)",
+	)
+	walletTxCounts := flag.String(
+		"wallet-tx-counts",
+		"",
+		"Comma-separated number of transactions of each wallet, e.g. 100,50,200, instead of splitting -txns evenly; -txns becomes their sum",
+	)
+	txTemplatePath := flag.String(
+		"tx-template",
+		"",
+		"JSON file listing the transactions every wallet sends, each with optional to, value (wei), data, gas, tip_gwei and max_fee_gwei; -txns defaults to their number times -wallets and must equal it when given",
+	)
+	autoWallets := flag.Bool(
+		"auto-wallets",
+		false,
+		"Derive wallets one by one and send only from those holding funds, until their balances cover -txns transactions (ignores -wallets)",
+	)
+	autoWalletsScan := flag.Int(
+		"auto-wallets-scan",
+		defaults.AutoWalletsScan,
+		"Most wallet indexes -auto-wallets derives while looking for funded wallets",
+	)
+	tipPercentile := flag.Float64(
+		"tip-percentile",
+		0,
+		"Price the tip at this percentile (0-100) of rewards paid in recent blocks; 0 uses the node's suggestion",
+	)
+	tipBlocks := flag.Uint64(
+		"tip-blocks",
+		defaults.TipBlocks,
+		"Number of recent blocks sampled for -tip-percentile",
+	)
+	runTimeout := flag.Duration(
+		"run-timeout",
+		0,
+		"Abort the run and print the partial summary after this long (e.g., 5m); 0 waits forever",
+	)
+	requireFunded := flag.Bool(
+		"require-funded",
+		false,
+		"Abort before sending if any wallet cannot afford a single transaction",
+	)
+	logFile := flag.String(
+		"log-file",
+		"",
+		"Also append logs to this file (console output is kept)",
+	)
+	logGzip := flag.Bool(
+		"log-gzip",
+		false,
+		"Gzip-compress the -log-file output, flushing every few seconds and when the run ends",
+	)
+	syslogTag := flag.String(
+		"syslog",
+		"",
+		"Also send logs to the local syslog daemon under this tag",
+	)
+	verifyMempool := flag.Bool(
+		"verify-mempool",
+		false,
+		"Shortly after each accepted broadcast, check the node still knows the transaction and count silently dropped ones as vanished",
+	)
+	verifyMempoolDelay := flag.Duration(
+		"verify-mempool-delay",
+		defaults.VerifyMempoolDelay,
+		"How long after a broadcast -verify-mempool checks for it",
+	)
+	waitReceipts := flag.Bool(
+		"wait-receipts",
+		false,
+		"Wait for the receipt of every successfully broadcast transaction",
+	)
+	receiptPollInterval := flag.Duration(
+		"receipt-poll-interval",
+		defaults.ReceiptPollInterval,
+		"How often to poll for receipts; lower values find receipts sooner but load the RPC more",
+	)
+	receiptTimeout := flag.Duration(
+		"receipt-timeout",
+		defaults.ReceiptTimeout,
+		"How long to wait for each transaction's receipt before counting it unconfirmed",
+	)
+	confirmBlockTimeout := flag.Uint64(
+		"confirm-block-timeout",
+		0,
+		"Also count a transaction unconfirmed once this many blocks are mined after its broadcast without it (0 disables); use -receipt-timeout 0 to go by blocks only",
+	)
+	shutdownGrace := flag.Duration(
+		"shutdown-grace",
+		defaults.ShutdownGrace,
+		"On Ctrl-C with -wait-receipts or -escalate-after, how long to keep collecting receipts of broadcast transactions before printing the summary",
+	)
+	maxInflight := flag.Int(
+		"max-inflight",
+		0,
+		"With -wait-receipts or -escalate-after, broadcast no more than this many transactions ahead of their confirmations; 0 disables the bound",
+	)
+	adaptiveErrorRate := flag.Float64(
+		"adaptive-error-rate",
+		0,
+		"Slow down when more than this share (0-1) of recent broadcasts fail: the send rate is halved, then recovers step by step up to the -wait pace; 0 disables it",
+	)
+	adaptiveWindow := flag.Int(
+		"adaptive-window",
+		defaults.AdaptiveWindow,
+		"Number of broadcasts the -adaptive-error-rate is measured over before each rate adjustment",
+	)
+	adaptiveMinTPS := flag.Float64(
+		"adaptive-min-tps",
+		defaults.AdaptiveMinTPS,
+		"Lowest send rate, in transactions per second, -adaptive-error-rate slows down to",
+	)
+	adaptiveStepTPS := flag.Float64(
+		"adaptive-step-tps",
+		defaults.AdaptiveStepTPS,
+		"Transactions per second added to the send rate after each window under -adaptive-error-rate",
+	)
+	label := flag.String(
+		"label",
+		"",
+		"Free-form tag for this run, embedded in the JSON report",
+	)
+	logLabel := flag.Bool(
+		"log-label",
+		false,
+		"Also tag every log record with the -label value",
+	)
+	reportPath := flag.String(
+		"report",
+		"",
+		"Write a JSON run report to this file when the run ends",
+	)
+	outputDir := flag.String(
+		"output-dir",
+		"",
+		"Directory to collect the run's artifacts in (report.json, latency.csv, hashes.txt, receipts.jsonl, txs.csv), named after -label and the start time; -report and -latency-csv still take precedence",
+	)
+	latencyBuckets := flag.String(
+		"latency-buckets",
+		"",
+		"Comma-separated upper bounds of the broadcast latency histogram, e.g. 10ms,50ms,250ms,1s (default 5ms to 10s)",
+	)
+	latencyCSV := flag.String(
+		"latency-csv",
+		"",
+		"Write the broadcast latency histogram to this CSV file when the run ends",
+	)
+	weightedSplit := flag.String(
+		"weighted-split",
+		"",
+		`JSON weights per wallet group, e.g. {"0-1": 80, "*": 20}; default splits transactions evenly`,
+	)
+	escalateAfter := flag.Duration(
+		"escalate-after",
+		0,
+		"Re-broadcast a transaction with bumped fees when it is not mined after this long (e.g., 30s); 0 disables",
+	)
+	escalatePercent := flag.Uint64(
+		"escalate-percent",
+		defaults.EscalatePercent,
+		"Percentage by which -escalate-after raises the tip and max fee (most nodes require >= 10)",
+	)
+	nonceStallTimeout := flag.Duration(
+		"nonce-stall-timeout",
+		0,
+		"With -wait-receipts or -escalate-after, warn when a wallet's on-chain nonce has not moved for this long while it has transactions unmined (e.g., 1m); 0 disables",
+	)
+	sharedFees := flag.Bool(
+		"shared-fees",
+		false,
+		"Estimate gas and fetch fee data once for all wallets instead of per wallet",
+	)
+	replayFeesPath := flag.String(
+		"replay-fees",
+		"",
+		"Build every wallet's batch with the gas limit and fees recorded in this JSON report of a previous run instead of querying the node",
+	)
+	value := flag.String(
+		"value",
+		strconv.Itoa(ethwallet.TransferValue),
+		"Amount of Wei each transaction carries",
+	)
+	to := flag.String(
+		"to",
+		"",
+		"Recipient of every transaction, a hex address or an ENS name (e.g. vitalik.eth) resolved through the RPC; empty sends each wallet's transactions to itself",
+	)
+	valueEth := flag.String(
+		"value-eth",
+		"",
+		"Amount of Ether each transaction carries, e.g. 0.01 (overrides -value)",
+	)
+	valueDist := flag.String(
+		"value-dist",
+		"",
+		"Draw each transaction's value from uniform:min,max, normal:mean,stddev or exponential:mean (Ether), clamped to the wallet's balance",
+	)
+	seed := flag.Uint64(
+		"seed",
+		0,
+		"Seed for -value-dist, -data-size, -retry-jitter and -broadcast-order shuffle; 0 picks a random seed, which is logged so the run can be repeated",
+	)
+	broadcastOrder := flag.String(
+		"broadcast-order",
+		string(defaults.BroadcastOrder),
+		"Order to broadcast transactions in: sequential (grouped by wallet), shuffle (random, from -seed) or interleave (one per wallet in turn)",
+	)
+	signerName := flag.String(
+		"signer",
+		string(defaults.Signer),
+		"Transaction signer: london (EIP-1559), eip155 (legacy with replay protection) or homestead (legacy without a chain ID, for chains predating EIP-155)",
+	)
+	dataSize := flag.Int(
+		"data-size",
+		0,
+		"Attach this many random bytes (from -seed) as calldata to every transaction, to test gas and acceptance of large payloads",
+	)
+	tagData := flag.Bool(
+		"tag-data",
+		false,
+		"Append the wallet index and nonce (4 + 8 bytes, big-endian) to every transaction's calldata, to identify it in block explorers",
+	)
+	walletFeeOverrides := flag.String(
+		"wallet-fee-overrides",
+		"",
+		`Per-wallet fees in Gwei as JSON keyed by wallet index, e.g. {"0": {"tip_gwei": "5"}, "3": {"max_fee_gwei": "40"}}`,
+	)
+	walletLabels := flag.String(
+		"wallet-labels",
+		"",
+		`Names shown in logs and reports instead of the address, as JSON keyed by wallet index, e.g. {"0": "sponsor", "1": "relayer"}`,
+	)
+	showFees := flag.Bool(
+		"show-fees",
+		false,
+		"Print a table of the base fee, tip, max fee and gas limit applied before sending",
+	)
+	explorerURL := flag.String(
+		"explorer-url",
+		"",
+		"Log a link to every accepted transaction, from a block explorer template with %s for the hash, e.g. https://sepolia.etherscan.io/tx/%s",
+	)
+	balanceUnit := flag.String(
+		"balance-unit",
+		defaults.BalanceUnit,
+		"Unit wallet balances are printed in: wei, gwei or ether",
+	)
+	nativeDecimals := flag.Int(
+		"native-decimals",
+		18,
+		"Decimals of the chain's native token, for Ether amounts in flags and output on custom chains",
+	)
+	maxFeeGwei := flag.String(
+		"max-fee-gwei",
+		"",
+		"Fee cap per gas in Gwei; the tip is filled in as min(suggested tip, max fee - base fee)",
+	)
+	maxGasPriceGwei := flag.String(
+		"max-gas-price-gwei",
+		"",
+		"Sanity ceiling in Gwei: abort before signing anything if the base fee plus the suggested tip exceeds it",
+	)
+	gasMultiplier := flag.Float64(
+		"gas-multiplier",
+		0,
+		fmt.Sprintf("Scale every gas estimate by this factor, e.g. 1.2, rounded up, instead of adding a fixed %d gas (0 keeps the fixed buffer)", ethwallet.GasBuffer),
+	)
+	minTipGwei := flag.String(
+		"min-tip-gwei",
+		"",
+		"Floor in Gwei for the priority fee; lower suggested tips are raised to it",
+	)
+	gasOracleURL := flag.String(
+		"gas-oracle-url",
+		"",
+		`HTTP gas oracle answering {"tip": ..., "maxFee": ..., "baseFee": ...} in Wei; fees fall back to the node when it fails`,
+	)
+	gasOracleTimeout := flag.Duration(
+		"gas-oracle-timeout",
+		rpc.DefaultOracleTimeout,
+		"Timeout of each gas oracle request",
+	)
+	faucetURL := flag.String(
+		"faucet-url",
+		"",
+		`HTTP faucet to fund every wallet from before the run: each address is POSTed as {"address": "0x..."} and the run waits for the funds`,
+	)
+	faucetRPS := flag.Float64(
+		"faucet-rps",
+		rpc.DefaultFaucetRPS,
+		"Most requests per second sent to -faucet-url; rate-limited requests are retried",
+	)
+	faucetWait := flag.Duration(
+		"faucet-wait",
+		txmanager.DefaultFaucetWait,
+		"How long to wait for the -faucet-url funds to land before starting the run anyway",
+	)
+	maxSpendEth := flag.String(
+		"max-spend-eth",
+		"",
+		"Cap in Ether, or the native token's unit under -native-decimals, on the total value + worst-case gas of the issued transactions",
+	)
+	balanceBlock := flag.String(
+		"balance-block",
+		"latest",
+		"Block wallet balances are reported at: latest, pending or a block number",
+	)
+	balanceConcurrency := flag.Int(
+		"balance-concurrency",
+		defaults.BalanceConcurrency,
+		"Maximum number of per-wallet requests (balances, nonces, faucet fundings) in flight at once",
+	)
+	logFormat := flag.String(
+		"log-format",
+		string(logger.FormatText),
+		"Log encoding: text, json, logfmt or msgpack",
+	)
+	logWorkerID := flag.Bool(
+		"log-worker-id",
+		false,
+		"Tag every log record of a wallet's goroutines with the wallet index",
+	)
+	otelEndpoint := flag.String(
+		"otel-endpoint",
+		"",
+		"OTLP/HTTP collector URL, e.g. http://localhost:4318, to export a trace span per transaction to",
+	)
+	statsdAddr := flag.String(
+		"statsd-addr",
+		"",
+		"StatsD server (host:port) to send sent, success and failed counters and broadcast latency to over UDP",
+	)
+	expectDrain := flag.Bool(
+		"expect-drain",
+		false,
+		"Count insufficient-funds rejections as drained wallets rather than failures",
+	)
+	broadcastToAll := flag.Bool(
+		"broadcast-to-all",
+		false,
+		"Send every transaction to all RPC URLs at once and log which accepted it first",
+	)
+	failFast := flag.Bool(
+		"fail-fast",
+		false,
+		"Stop the run at the first failed broadcast and print that error",
+	)
+	consecutiveFailureLimit := flag.Int(
+		"consecutive-failure-limit",
+		0,
+		"Stop the run after this many broadcasts in a row failed, e.g. on a wrong chain ID; any success resets the count (0 disables)",
+	)
+	typedDataPath := flag.String(
+		"typed-data",
+		"",
+		"EIP-712 typed-data JSON file every wallet signs once per transaction instead of sending transfers",
+	)
+	verifier := flag.String(
+		"verifier",
+		"",
+		"Contract address the typed-data signatures are sent to as verify(bytes32,bytes) calls",
+	)
+	simulate := flag.Bool(
+		"simulate",
+		false,
+		"Run every signed transaction through eth_call at the latest block and skip those that would revert, with the later nonces of their wallet",
+	)
+	retries := flag.Int(
+		"retries",
+		0,
+		"Extra attempts for a broadcast that fails with a retryable error",
+	)
+	nonceRetries := flag.Int(
+		"nonce-retries",
+		0,
+		"Times a broadcast rejected with nonce too low is re-signed with the wallet's nonce resynced from the chain, apart from -retries",
+	)
+	retryDelay := flag.Duration(
+		"retry-delay",
+		defaults.RetryDelay,
+		"Delay between broadcast attempts",
+	)
+	captureErrors := flag.Int(
+		"capture-errors",
+		0,
+		"Keep the wallet, nonce, hash and full error of the first N failed broadcasts and print them at the end",
+	)
+	retryJitter := flag.String(
+		"retry-jitter",
+		string(defaults.RetryJitter),
+		"Randomize each retry delay from -seed: none, full (0 to -retry-delay) or equal (half of -retry-delay plus 0 to the other half)",
+	)
+	retryableErrors := flag.String(
+		"retryable-errors",
+		"",
+		"File of extra retryable error patterns (case-insensitive regexps, one per line), added to the defaults",
+	)
+	dumpRawTxs := flag.String(
+		"dump-raw-txs",
+		"",
+		"Sign every transaction, write their raw RLP hex to this file one per line and exit without broadcasting",
+	)
+	checkpointPath := flag.String(
+		"checkpoint",
+		"",
+		"File the run's progress is saved to, for -resume (defaults to the -resume file)",
+	)
+	checkpointInterval := flag.Duration(
+		"checkpoint-interval",
+		defaults.CheckpointInterval,
+		"How often the progress is saved to -checkpoint; it is always saved at the end of the run",
+	)
+	summaryInterval := flag.Duration(
+		"summary-interval",
+		0,
+		"Log a running summary (sent, success, failed, tx/s) this often while broadcasting, e.g. 30s; 0 disables it",
+	)
+	tui := flag.Bool(
+		"tui",
+		false,
+		"Show a live dashboard of the broadcasts (per-wallet progress, tx/s, success, failed, recent errors) instead of logging them; needs a terminal",
+	)
+	resumePath := flag.String(
+		"resume",
+		"",
+		"Checkpoint file of an interrupted run: transactions it already sent are not sent again",
+	)
+	warmup := flag.Bool(
+		"warmup",
+		false,
+		"Send one transaction per wallet first and abort the run unless all of them are accepted",
+	)
+	assumeYes := flag.Bool(
+		"yes",
+		false,
+		"Do not ask for confirmation before sending on a known mainnet",
+	)
+	mode := flag.String(
+		"mode",
+		modeRun,
+		"What to do: run sends the transactions; pending prints how many transactions each wallet has stuck in the mempool and exits",
+	)
+	estimateCost := flag.Bool(
+		"estimate-cost",
+		false,
+		"Print the projected total gas and value spend of the run at current fees and exit without sending",
+	)
+	probeMempool := flag.Int(
+		"probe-mempool",
+		0,
+		"Send up to this many transactions from the first wallet until the node reports a full mempool, print the per-account limit and exit",
+	)
+	validate := flag.Bool(
+		"validate",
+		false,
+		"Validate the configuration, print it with the mnemonic redacted and exit without contacting the RPC",
+	)
+	selfTest := flag.Bool(
+		"self-test",
+		false,
+		"Sign a known transaction with the first wallet and -signer offline, check that its sender recovers to the wallet, print pass or fail and exit",
+	)
+	printConfigJSON := flag.Bool(
+		"print-config",
+		false,
+		"Print the effective configuration as one line of JSON at startup, with the mnemonic and private key redacted",
+	)
 
 	flag.Parse()
 
-	// Input validation
-	if *mnemonic == "" {
-		fmt.Println("Error: mnemonic is required")
-		flag.Usage()
-		os.Exit(1)
+	// Input validation: problems collects every invalid flag so that they
+	// are all reported at once instead of one per attempt.
+	var problems []error
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if *mnemonic == "-" {
+		secret, err := readSecret("Mnemonic: ")
+		if err != nil {
+			problem("failed to read the mnemonic from stdin: %v", err)
+		}
+		*mnemonic = strings.Join(strings.Fields(secret), " ")
+	}
+
+	rpcURLs := rpc.SplitURLs(*rpcURL)
+
+	if *privateKey != "" {
+		// A private key is one wallet: default -wallets to it unless given.
+		walletsSet := false
+		flag.Visit(func(f *flag.Flag) { walletsSet = walletsSet || f.Name == "wallets" })
+		if !walletsSet {
+			*wallets = 1
+		}
+	}
+
+	var derivation icarus.DerivationPath
+	if *accountIndex >= 1<<31 || *changeIndex >= 1<<31 {
+		problem("account-index and change-index must be < %d", 1<<31)
+	} else {
+		derivation = icarus.DerivationPath{Account: uint32(*accountIndex), Change: uint32(*changeIndex)}
+	}
+
+	if *selfTest && *rpcURL == "" {
+		// The self-test signs offline and needs no RPC.
+		*rpcURL = rpc.NullURL
+	}
+
+	// Ether amounts below are parsed with the native decimals.
+	if err := ethwallet.SetNativeDecimals(*nativeDecimals); err != nil {
+		problems = append(problems, err)
+	}
+
+	var rpcEndpoints map[string]rpc.EndpointConfig
+	if *rpcConfig != "" {
+		var err error
+		rpcEndpoints, err = rpc.LoadEndpointConfigs(*rpcConfig)
+		if err != nil {
+			problems = append(problems, err)
+		}
+		for url := range rpcEndpoints {
+			if !slices.Contains(rpcURLs, url) {
+				problem("rpc-config names %s, which is not one of the RPC URLs", url)
+			}
+		}
+	}
+
+	var endpointWeights []float64
+	if *rpcWeights != "" {
+		var err error
+		endpointWeights, err = rpc.ParseWeights(*rpcWeights)
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if *statsdAddr != "" {
+		if _, _, err := net.SplitHostPort(*statsdAddr); err != nil {
+			problem("statsd-addr must be host:port: %v", err)
+		}
+	}
+
+	if *mode != modeRun && *mode != modePending {
+		problem("unknown mode %q (want %s or %s)", *mode, modeRun, modePending)
+	}
+
+	if *noWait {
+		*wait = 0
+	}
+
+	if *logLevel < int(logger.DEBUG) || *logLevel > int(logger.ERROR) {
+		problem("log-level must be between 0 and 3")
+	}
+	if *logGzip && *logFile == "" {
+		problem("log-gzip needs log-file")
+	}
+
+	if err := logger.SetFormat(logger.Format(*logFormat)); err != nil {
+		problems = append(problems, err)
+	}
+
+	txValue, ok := new(big.Int).SetString(*value, 10)
+	if !ok || txValue.Sign() < 0 {
+		problem("value must be a non-negative integer amount of Wei")
+	}
+
+	if *valueEth != "" {
+		var err error
+		txValue, err = ethwallet.EtherToWei(*valueEth)
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	var valueDistribution *ethwallet.ValueDistribution
+	if *valueDist != "" {
+		if *valueEth != "" || *value != strconv.Itoa(ethwallet.TransferValue) {
+			problem("value-dist cannot be combined with value or value-eth")
+		}
+		var err error
+		valueDistribution, err = ethwallet.ParseValueDistribution(*valueDist)
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	var maxFee *big.Int
+	if *maxFeeGwei != "" {
+		var err error
+		maxFee, err = ethwallet.GweiToWei(*maxFeeGwei)
+		if err != nil || maxFee.Sign() == 0 {
+			problem("max-fee-gwei must be a positive amount of Gwei")
+			maxFee = nil
+		}
+	}
+
+	var maxGasPrice *big.Int
+	if *maxGasPriceGwei != "" {
+		var err error
+		maxGasPrice, err = ethwallet.GweiToWei(*maxGasPriceGwei)
+		if err != nil || maxGasPrice.Sign() == 0 {
+			problem("max-gas-price-gwei must be a positive amount of Gwei")
+			maxGasPrice = nil
+		}
+	}
+
+	var minTip *big.Int
+	if *minTipGwei != "" {
+		var err error
+		minTip, err = ethwallet.GweiToWei(*minTipGwei)
+		if err != nil || minTip.Sign() == 0 {
+			problem("min-tip-gwei must be a positive amount of Gwei")
+			minTip = nil
+		}
+	}
+
+	var gasOracle *rpc.OracleClient
+	if *gasOracleURL != "" {
+		if u, err := url.Parse(*gasOracleURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problem("gas-oracle-url must be an http(s) URL")
+		}
+		if *gasOracleTimeout <= 0 {
+			problem("gas-oracle-timeout must be positive")
+		}
+		gasOracle = rpc.NewOracleClient(*gasOracleURL, *gasOracleTimeout)
+	}
+
+	var faucet *rpc.FaucetClient
+	if *faucetURL != "" {
+		if u, err := url.Parse(*faucetURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problem("faucet-url must be an http(s) URL")
+		}
+		if *faucetRPS <= 0 {
+			problem("faucet-rps must be positive")
+		}
+		faucet = rpc.NewFaucetClient(*faucetURL, *faucetRPS, 0)
+	}
+
+	var maxSpend *big.Int
+	if *maxSpendEth != "" {
+		var err error
+		maxSpend, err = ethwallet.EtherToWei(*maxSpendEth)
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	balanceBlockNumber, err := rpc.ParseBlockNumber(*balanceBlock)
+	if err != nil {
+		problems = append(problems, err)
+	}
+
+	var splitWeights []float64
+	if *weightedSplit != "" && *wallets > 0 {
+		var err error
+		splitWeights, err = txmanager.ParseWeightedSplit(*weightedSplit, *wallets)
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	var txCounts []int
+	if *walletTxCounts != "" {
+		var err error
+		txCounts, err = txmanager.ParseWalletTxCounts(*walletTxCounts)
+		if err != nil {
+			problems = append(problems, err)
+		}
+		*txCount = 0
+		for _, n := range txCounts {
+			*txCount += n
+		}
+	}
+
+	var txTemplate []ethwallet.TxSpec
+	if *txTemplatePath != "" {
+		var err error
+		txTemplate, err = txmanager.LoadTxTemplate(*txTemplatePath)
+		if err != nil {
+			problems = append(problems, err)
+		}
+		// -txns follows the template unless given; Validate rejects a conflicting one.
+		txCountSet := false
+		flag.Visit(func(f *flag.Flag) { txCountSet = txCountSet || f.Name == "txns" })
+		if !txCountSet {
+			*txCount = len(txTemplate) * *wallets
+		}
+	}
+
+	jitter, err := txmanager.ParseJitterMode(*retryJitter)
+	if err != nil {
+		problems = append(problems, err)
+	}
+
+	order, err := txmanager.ParseBroadcastOrder(*broadcastOrder)
+	if err != nil {
+		problems = append(problems, err)
+	}
+
+	signer, err := ethwallet.ParseSigner(*signerName)
+	if err != nil {
+		problems = append(problems, err)
+	}
+
+	var buckets []time.Duration
+	if *latencyBuckets != "" {
+		if buckets, err = txmanager.ParseLatencyBuckets(*latencyBuckets); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	retryPatterns := ethwallet.DefaultRetryablePatterns
+	if *retryableErrors != "" {
+		extra, err := ethwallet.LoadRetryablePatterns(*retryableErrors)
+		if err != nil {
+			problems = append(problems, err)
+		}
+		retryPatterns = append(retryPatterns[:len(retryPatterns):len(retryPatterns)], extra...)
+	}
+	var retryable func(error) bool
+	if retryClassifier, err := ethwallet.NewRetryClassifier(retryPatterns); err != nil {
+		problems = append(problems, err)
+	} else {
+		retryable = retryClassifier.Retryable
 	}
 
-	if *rpcURL == "" {
-		fmt.Println("Error: RPC URL is required")
+	var typedData *apitypes.TypedData
+	if *typedDataPath != "" {
+		data, err := ethwallet.LoadTypedData(*typedDataPath)
+		if err != nil {
+			problems = append(problems, err)
+		} else {
+			typedData = &data
+		}
+	}
+
+	var verifierAddress *common.Address
+	if *verifier != "" {
+		if *typedDataPath == "" {
+			problem("verifier requires typed-data")
+		}
+		if !common.IsHexAddress(*verifier) {
+			problem("invalid verifier address %q", *verifier)
+		} else {
+			address := common.HexToAddress(*verifier)
+			verifierAddress = &address
+		}
+	}
+
+	var feeOverrides map[int]ethwallet.FeeOverride
+	if *walletFeeOverrides != "" && *wallets > 0 {
+		var err error
+		feeOverrides, err = txmanager.ParseFeeOverrides(*walletFeeOverrides, *wallets)
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	var labels map[int]string
+	if *walletLabels != "" && *wallets > 0 {
+		var err error
+		labels, err = txmanager.ParseWalletLabels(*walletLabels, *wallets)
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	var replayFees map[int]*ethwallet.FeeData
+	if *replayFeesPath != "" {
+		var err error
+		replayFees, err = txmanager.LoadReplayFees(*replayFeesPath)
+		if err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	var resume *txmanager.Checkpoint
+	if *resumePath != "" {
+		var err error
+		resume, err = txmanager.LoadCheckpoint(*resumePath)
+		if err != nil {
+			problems = append(problems, err)
+		}
+		if *checkpointPath == "" {
+			*checkpointPath = *resumePath
+		}
+	}
+
+	cfg := icarus.Config{
+		RpcUrl:        *rpcURL,
+		RpcClientName: *rpcClientName,
+		RpcTrace:      *rpcTrace,
+		RpcEndpoints:  rpcEndpoints,
+		RpcWeights:    endpointWeights,
+		Wait:          *wait,
+		WalletsNumber: *wallets,
+		TxNumber:      *txCount,
+		Mnemonic:      *mnemonic,
+		PrivateKey:    *privateKey,
+		Derivation:    derivation,
+		TipPercentile: *tipPercentile,
+		TipBlocks:     *tipBlocks,
+		RunTimeout:    *runTimeout,
+		ShutdownGrace: *shutdownGrace,
+		RequireFunded: *requireFunded,
+		StreamWallets: *streamWallets,
+
+		AutoWallets:     *autoWallets,
+		AutoWalletsScan: *autoWalletsScan,
+		WalletTxCounts:  txCounts,
+		TxTemplate:      txTemplate,
+
+		GasMultiplier: *gasMultiplier,
+
+		WaitReceipts:        *waitReceipts,
+		ReceiptPollInterval: *receiptPollInterval,
+		ReceiptTimeout:      *receiptTimeout,
+		ConfirmBlockTimeout: *confirmBlockTimeout,
+		EscalateAfter:       *escalateAfter,
+		VerifyMempool:       *verifyMempool,
+		VerifyMempoolDelay:  *verifyMempoolDelay,
+		EscalatePercent:     *escalatePercent,
+		NonceStallTimeout:   *nonceStallTimeout,
+
+		Label:          *label,
+		ReportPath:     *reportPath,
+		LatencyBuckets: buckets,
+		LatencyCSV:     *latencyCSV,
+
+		SplitWeights: splitWeights,
+		SharedFees:   *sharedFees,
+		ReplayFees:   replayFees,
+		Value:        txValue,
+		To:           *to,
+		ValueDist:    valueDistribution,
+		Seed:         *seed,
+		DataSize:     *dataSize,
+		TagData:      *tagData,
+		MaxFee:       maxFee,
+		MaxGasPrice:  maxGasPrice,
+		MinTip:       minTip,
+		GasOracle:    gasOracle,
+		Faucet:       faucet,
+		FaucetWait:   *faucetWait,
+		MaxSpend:     maxSpend,
+		FeeOverrides: feeOverrides,
+		WalletLabels: labels,
+		ShowFees:     *showFees,
+		ExplorerURL:  *explorerURL,
+		BalanceBlock: balanceBlockNumber,
+
+		BalanceConcurrency: *balanceConcurrency,
+		BalanceUnit:        *balanceUnit,
+		ExpectDrain:        *expectDrain,
+		BroadcastToAll:     *broadcastToAll,
+		BroadcastOrder:     order,
+		Signer:             signer,
+		PerWalletTPS:       *perWalletTPS,
+		MaxInflight:        *maxInflight,
+		FailFast:           *failFast,
+
+		AdaptiveErrorRate: *adaptiveErrorRate,
+		AdaptiveWindow:    *adaptiveWindow,
+		AdaptiveMinTPS:    *adaptiveMinTPS,
+		AdaptiveStepTPS:   *adaptiveStepTPS,
+
+		ConsecutiveFailureLimit: *consecutiveFailureLimit,
+		Simulate:                *simulate,
+
+		TypedData: typedData,
+		Verifier:  verifierAddress,
+		AssumeYes: *assumeYes,
+
+		Retries:     *retries,
+		RetryDelay:  *retryDelay,
+		RetryJitter: jitter,
+
+		NonceRetries: *nonceRetries,
+
+		CaptureErrors: *captureErrors,
+		Retryable:     retryable,
+		Warmup:        *warmup,
+		LogWorkerID:   *logWorkerID,
+
+		CheckpointPath:     *checkpointPath,
+		CheckpointInterval: *checkpointInterval,
+		Resume:             resume,
+		SummaryInterval:    *summaryInterval,
+		DumpRawTxs:         *dumpRawTxs,
+	}
+
+	if err := cfg.Validate(); err != nil {
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			problems = append(problems, joined.Unwrap()...)
+		} else {
+			problems = append(problems, err)
+		}
+	}
+	if len(problems) > 0 {
+		for _, err := range problems {
+			fmt.Printf("Error: %v\n", err)
+		}
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	if *validate {
+		fmt.Println("Configuration is valid:")
+		printConfig()
+		return
+	}
+
+	if *selfTest {
+		result, err := icarus.SelfTest(cfg)
+		switch {
+		case err != nil:
+			fmt.Printf("Self-test FAILED (signer %s): %v\n", result.Signer, err)
+			os.Exit(1)
+		case !result.Passed():
+			fmt.Printf("Self-test FAILED (signer %s): transaction %s recovers to %s, not the wallet %s\n", result.Signer, result.Hash, result.Sender, result.Address)
+			os.Exit(1)
+		}
+		fmt.Printf("Self-test passed (signer %s): transaction %s recovers to the wallet %s\n", result.Signer, result.Hash, result.Address)
+		return
+	}
+
 	// Set the minimum log level
 	logger.SetMinLevel(logger.Level(*logLevel))
 
-	// Initialize transaction manager
-	txManager := &txmanager.TxManager{
-		RpcUrl:       *rpcURL,
-		WaitMilis:    int(*wait / time.Millisecond),
-		WalletsNumber: *wallets,
-		TxNumber:     *txCount,
-		Mu:           &sync.Mutex{},
-		Mnemonic:     *mnemonic,
-		Success:      0,
-		Failed:       0,
+	if *logLabel && *label != "" {
+		logger.SetLabel(*label)
 	}
 
-	// Start transaction processing
-	txManager.Run()
+	closeLog := func() {}
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			fmt.Printf("Error: failed to open log file: %v\n", err)
+			os.Exit(1)
+		}
+		var sink io.WriteCloser = f
+		if *logGzip {
+			sink = logger.NewGzipSink(f, logger.DefaultGzipFlushInterval)
+		}
+		closeLog = func() { sink.Close() }
+		defer closeLog()
+		logger.AddOutput(sink)
+	}
+	// exit closes the log file first: os.Exit skips deferred calls, and a
+	// gzip-compressed log would be left without its trailer.
+	exit := func(code int) {
+		closeLog()
+		os.Exit(code)
+	}
+
+	if *syslogTag != "" {
+		if err := logger.SetSyslog(*syslogTag); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			exit(1)
+		}
+	}
+
+	// flushTraces exports any buffered spans; os.Exit skips deferred calls,
+	// so it is invoked explicitly once the run is over.
+	flushTraces := func() {}
+	if *otelEndpoint != "" {
+		shutdown, err := setupTracing(context.Background(), *otelEndpoint, *label)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			exit(1)
+		}
+		flushTraces = func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				logger.Warnf("failed to flush traces: %v", err)
+			}
+		}
+	}
+
+	if *statsdAddr != "" {
+		statsd, err := metrics.DialStatsd(*statsdAddr, "icarus")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			exit(1)
+		}
+		defer statsd.Close()
+		cfg.Metrics = statsd
+	}
+
+	if *printConfigJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(cfg.Redacted()); err != nil {
+			fmt.Printf("Error: failed to encode configuration: %v\n", err)
+			exit(1)
+		}
+	}
+
+	if *mode == modePending {
+		pending, err := icarus.PendingTransactions(cfg)
+		if err != nil {
+			logger.Errorf("%v", err)
+			exit(1)
+		}
+		printPending(pending)
+		return
+	}
+
+	if *probeMempool > 0 {
+		probe, err := icarus.ProbeMempool(cfg, *probeMempool)
+		if err != nil {
+			logger.Errorf("%v", err)
+			exit(1)
+		}
+		if probe.Limited {
+			fmt.Printf("Per-account mempool limit: %d pending transactions (rejected with: %v)\n", probe.Accepted, probe.Err)
+		} else {
+			fmt.Printf("No mempool limit reached: the node accepted all %d transactions\n", probe.Accepted)
+		}
+		return
+	}
+
+	if *estimateCost {
+		estimate, err := icarus.EstimateCost(cfg)
+		if err != nil {
+			logger.Errorf("%v", err)
+			exit(1)
+		}
+		fmt.Printf("Transactions:   %d (gas limit %d, max fee %s wei/gas)\n", estimate.Transactions, estimate.GasLimit, estimate.MaxFeePerGas)
+		fmt.Printf("Gas (max):      %s wei (%s ether)\n", estimate.GasWei, ethwallet.FormatEther(estimate.GasWei))
+		fmt.Printf("Value:          %s wei (%s ether)\n", estimate.ValueWei, ethwallet.FormatEther(estimate.ValueWei))
+		fmt.Printf("Total (max):    %s wei (%s ether)\n", estimate.TotalWei, estimate.TotalEther())
+		return
+	}
+
+	// The first Ctrl-C stops the run gracefully, a second one exits at once.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	interrupt := make(chan struct{})
+	go func() {
+		<-signals
+		close(interrupt)
+		<-signals
+		fmt.Println("Interrupted again, exiting")
+		exit(130)
+	}()
+	cfg.Interrupt = interrupt
+
+	closeArtifacts := func() {}
+	if *outputDir != "" {
+		out, err := openArtifacts(*outputDir, *label, time.Now())
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			exit(1)
+		}
+		if cfg.ReportPath == "" {
+			cfg.ReportPath = out.Path("report.json")
+		}
+		if cfg.LatencyCSV == "" {
+			cfg.LatencyCSV = out.Path("latency.csv")
+		}
+		cfg.OnSigned, cfg.OnBroadcast, cfg.OnConfirmed = out.Hook(cfg.OnSigned, cfg.OnBroadcast, cfg.OnConfirmed)
+		closeArtifacts = func() {
+			if err := out.Close(); err != nil {
+				logger.Errorf("%v", err)
+			}
+		}
+		logger.Infof("Writing artifacts to %s*", out.Path(""))
+	}
+
+	stopDashboard := func() {}
+	if *tui {
+		if dash := newDashboard(os.Stdout); dash != nil {
+			cfg.Events = dash.events
+			stopDashboard = dash.Stop
+		} else {
+			logger.Warnf("-tui needs a terminal, logging plainly instead")
+		}
+	}
+
+	_, err = icarus.Execute(cfg)
+	stopDashboard()
+	closeArtifacts()
+	flushTraces()
+	if err != nil {
+		logger.Errorf("%v", err)
+		exit(1)
+	}
+}
+
+// printPending prints the pending transaction count of every wallet as a table.
+func printPending(wallets []icarus.WalletPending) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WALLET\tNONCE\tPENDING NONCE\tPENDING")
+	var total uint64
+	for _, wallet := range wallets {
+		if wallet.Err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\terror: %v\n", wallet.Name(), wallet.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", wallet.Name(), wallet.Nonce, wallet.PendingNonce, wallet.Pending())
+		total += wallet.Pending()
+	}
+	w.Flush()
+	fmt.Printf("Total pending: %d\n", total)
+}
+
+// readSecret reads one line from stdin: after prompting on stderr and without
+// echo when stdin is a terminal, or as piped otherwise.
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(secret), err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return line, nil
+}
+
+// printConfig prints every flag with its resolved value, redacting the mnemonic
+// and private key.
+func printConfig() {
+	flag.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		if f.Name == "mnemonic" && value != "" {
+			value = fmt.Sprintf("<redacted, %d words>", len(strings.Fields(value)))
+		}
+		if f.Name == "private-key" && value != "" {
+			value = "<redacted>"
+		}
+		fmt.Printf("  -%s=%s\n", f.Name, value)
+	})
 }
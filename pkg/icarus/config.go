@@ -0,0 +1,290 @@
+package icarus
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/rpc"
+	"github.com/mdtosif/icarus/internal/txmanager"
+)
+
+// Defaults of DefaultConfig that are not defaults of the manager itself.
+const (
+	DefaultWallets            = 10
+	DefaultTxNumber           = 100
+	DefaultWait               = 10 * time.Millisecond
+	DefaultTipBlocks          = 20
+	DefaultShutdownGrace      = 10 * time.Second
+	DefaultRetryDelay         = 500 * time.Millisecond
+	DefaultCheckpointInterval = 10 * time.Second
+)
+
+// DefaultConfig returns the configuration the command runs with when no flag
+// is given. Fill in RpcUrl and Mnemonic (or PrivateKey) and adjust the rest.
+func DefaultConfig() Config {
+	return Config{
+		WalletsNumber:   DefaultWallets,
+		TxNumber:        DefaultTxNumber,
+		Wait:            DefaultWait,
+		AutoWalletsScan: txmanager.DefaultAutoWalletsScan,
+		TipBlocks:       DefaultTipBlocks,
+
+		ReceiptPollInterval: txmanager.DefaultReceiptPollInterval,
+		ReceiptTimeout:      txmanager.DefaultReceiptTimeout,
+		VerifyMempoolDelay:  txmanager.DefaultVerifyMempoolDelay,
+		EscalatePercent:     txmanager.DefaultEscalatePercent,
+		ShutdownGrace:       DefaultShutdownGrace,
+
+		AdaptiveWindow:  txmanager.DefaultAdaptiveWindow,
+		AdaptiveMinTPS:  txmanager.DefaultAdaptiveMinTPS,
+		AdaptiveStepTPS: txmanager.DefaultAdaptiveStepTPS,
+
+		RetryDelay:     DefaultRetryDelay,
+		RetryJitter:    JitterNone,
+		BroadcastOrder: OrderSequential,
+		Signer:         SignerLondon,
+
+		BalanceConcurrency: txmanager.DefaultBalanceConcurrency,
+		BalanceUnit:        ethwallet.UnitEther,
+		CheckpointInterval: DefaultCheckpointInterval,
+	}
+}
+
+// Validate checks cfg as a whole and returns every problem found, joined
+// with errors.Join, so that a user can fix all of them in one pass. Problems
+// are worded after the flags of the command, which match the fields.
+func (cfg Config) Validate() error {
+	var problems []error
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	switch {
+	case cfg.PrivateKey != "":
+		if cfg.Mnemonic != "" {
+			problem("mnemonic and private-key cannot be combined")
+		}
+		if _, err := ethwallet.WalletFromHexKey(cfg.PrivateKey, nil, 0); err != nil {
+			problems = append(problems, err)
+		}
+		if cfg.WalletsNumber != 1 {
+			problem("private-key gives a single wallet: wallets must be 1")
+		}
+		if cfg.StreamWallets {
+			problem("stream-wallets cannot be combined with private-key")
+		}
+		if cfg.Derivation != (DerivationPath{}) {
+			problem("account-index and change-index derive from the mnemonic and cannot be combined with private-key")
+		}
+	case cfg.Mnemonic == "":
+		problem("mnemonic or private-key is required")
+	default:
+		if err := ethwallet.ValidateMnemonic(cfg.Mnemonic); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	rpcURLs := rpc.SplitURLs(cfg.RpcUrl)
+	if cfg.RpcUrl == "" {
+		problem("RPC URL is required")
+	} else {
+		for _, u := range rpcURLs {
+			if err := validateRPCURL(u); err != nil {
+				problems = append(problems, err)
+			}
+		}
+	}
+	if cfg.BroadcastToAll && len(rpcURLs) < 2 {
+		problem("broadcast-to-all needs several comma-separated RPC URLs")
+	}
+	if cfg.RpcWeights != nil {
+		if len(cfg.RpcWeights) != len(rpcURLs) {
+			problem("rpc-weights has %d weights for %d RPC URLs", len(cfg.RpcWeights), len(rpcURLs))
+		}
+		if cfg.BroadcastToAll {
+			problem("rpc-weights cannot be combined with broadcast-to-all, which uses every endpoint")
+		}
+	}
+
+	if cfg.WalletsNumber <= 0 {
+		problem("wallets must be > 0")
+	} else if err := ethwallet.ValidateDerivationPaths(cfg.Derivation, cfg.WalletsNumber); err != nil {
+		problems = append(problems, err)
+	}
+	if cfg.TxNumber < 0 {
+		problem("txns must be >= 0")
+	}
+	if cfg.Wait < 0 {
+		problem("wait must be >= 0")
+	}
+	if cfg.PerWalletTPS < 0 {
+		problem("per-wallet-tps must be >= 0")
+	}
+	if cfg.BalanceConcurrency <= 0 {
+		problem("balance-concurrency must be > 0")
+	}
+	if err := ethwallet.ValidateBalanceUnit(cfg.BalanceUnit); err != nil {
+		problems = append(problems, err)
+	}
+	if cfg.GasMultiplier != 0 && !(cfg.GasMultiplier >= 1 && cfg.GasMultiplier <= 10) {
+		problem("gas-multiplier must be between 1 and 10, or 0 for the fixed buffer")
+	}
+	if cfg.ExplorerURL != "" {
+		if err := txmanager.ValidateExplorerURL(cfg.ExplorerURL); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if cfg.RunTimeout < 0 || cfg.ReceiptTimeout < 0 || cfg.ShutdownGrace < 0 {
+		problem("run-timeout, receipt-timeout and shutdown-grace must be >= 0")
+	}
+	if cfg.ReceiptPollInterval <= 0 {
+		problem("receipt-poll-interval must be > 0")
+	}
+	if cfg.ConfirmBlockTimeout > 0 && !cfg.WaitReceipts && cfg.EscalateAfter <= 0 {
+		problem("confirm-block-timeout needs wait-receipts or escalate-after")
+	}
+	if cfg.EscalateAfter < 0 {
+		problem("escalate-after must be >= 0")
+	}
+	if cfg.EscalateAfter > 0 && cfg.EscalatePercent == 0 {
+		problem("escalate-percent must be > 0")
+	}
+	if cfg.MaxInflight < 0 {
+		problem("max-inflight must be >= 0")
+	}
+	if cfg.NonceStallTimeout < 0 {
+		problem("nonce-stall-timeout must be >= 0")
+	} else if cfg.NonceStallTimeout > 0 && !cfg.WaitReceipts && cfg.EscalateAfter <= 0 {
+		problem("nonce-stall-timeout needs wait-receipts or escalate-after")
+	}
+	if cfg.ConsecutiveFailureLimit < 0 {
+		problem("consecutive-failure-limit must be >= 0")
+	}
+	if cfg.MaxInflight > 0 && !cfg.WaitReceipts && cfg.EscalateAfter == 0 {
+		problem("max-inflight needs wait-receipts or escalate-after")
+	}
+	if cfg.AdaptiveErrorRate < 0 || cfg.AdaptiveErrorRate >= 1 {
+		problem("adaptive-error-rate must be between 0 and 1")
+	}
+	if cfg.AdaptiveErrorRate > 0 && cfg.Wait <= 0 {
+		problem("adaptive-error-rate scales the -wait pace: wait must be > 0")
+	}
+	if cfg.AdaptiveWindow <= 0 {
+		problem("adaptive-window must be > 0")
+	}
+	if cfg.AdaptiveMinTPS <= 0 || cfg.AdaptiveStepTPS <= 0 {
+		problem("adaptive-min-tps and adaptive-step-tps must be > 0")
+	}
+	if cfg.VerifyMempoolDelay < 0 {
+		problem("verify-mempool-delay must be >= 0")
+	}
+
+	if cfg.TipPercentile < 0 || cfg.TipPercentile > 100 {
+		problem("tip-percentile must be between 0 and 100")
+	}
+	if cfg.TipPercentile > 0 && cfg.TipBlocks == 0 {
+		problem("tip-blocks must be > 0 when tip-percentile is set")
+	}
+	if cfg.MinTip != nil && cfg.MaxFee != nil && cfg.MinTip.Cmp(cfg.MaxFee) >= 0 {
+		problem("min-tip-gwei must be below max-fee-gwei")
+	}
+
+	if cfg.Signer.Legacy() {
+		if cfg.TipPercentile > 0 || cfg.MinTip != nil {
+			problem("signer %s sends legacy transactions priced by gas price: tip-percentile and min-tip-gwei need the london signer", cfg.Signer)
+		}
+		if cfg.EscalateAfter > 0 || cfg.NonceRetries > 0 {
+			problem("signer %s sends legacy transactions: escalate-after and nonce-retries re-sign EIP-1559 transactions only", cfg.Signer)
+		}
+	}
+
+	if cfg.ReplayFees != nil && (cfg.SharedFees || cfg.GasOracle != nil || cfg.MaxFee != nil || cfg.MinTip != nil || cfg.TipPercentile > 0) {
+		problem("replay-fees cannot be combined with shared-fees, gas-oracle-url, max-fee-gwei, min-tip-gwei or tip-percentile")
+	}
+
+	if cfg.DataSize < 0 {
+		problem("data-size must be >= 0")
+	}
+	if cfg.DataSize > 0 && cfg.Verifier != nil {
+		problem("data-size cannot be combined with verifier, which sets the calldata")
+	}
+	if cfg.To != "" && !common.IsHexAddress(cfg.To) && !rpc.IsENSName(cfg.To) {
+		problem("to must be a hex address or an ENS name, got %q", cfg.To)
+	}
+	if cfg.To != "" && cfg.Verifier != nil {
+		problem("to cannot be combined with verifier, which is the recipient")
+	}
+
+	if cfg.Retries < 0 || cfg.RetryDelay < 0 {
+		problem("retries and retry-delay must be >= 0")
+	}
+	if cfg.NonceRetries < 0 {
+		problem("nonce-retries must be >= 0")
+	}
+	if cfg.CaptureErrors < 0 {
+		problem("capture-errors must be >= 0")
+	}
+	if cfg.CheckpointInterval < 0 {
+		problem("checkpoint-interval must be >= 0")
+	}
+	if cfg.SummaryInterval < 0 {
+		problem("summary-interval must be >= 0")
+	}
+	if cfg.StreamWallets && (cfg.RequireFunded || cfg.TypedData != nil) {
+		problem("stream-wallets cannot be combined with require-funded or typed-data")
+	}
+	if cfg.AutoWallets && (cfg.PrivateKey != "" || cfg.StreamWallets || cfg.SplitWeights != nil || cfg.Resume != nil) {
+		problem("auto-wallets cannot be combined with private-key, stream-wallets, weighted-split or resume")
+	}
+	if cfg.WalletTxCounts != nil {
+		if len(cfg.WalletTxCounts) != cfg.WalletsNumber {
+			problem("wallet-tx-counts has %d counts, wallets is %d", len(cfg.WalletTxCounts), cfg.WalletsNumber)
+		}
+		if cfg.SplitWeights != nil || cfg.AutoWallets {
+			problem("wallet-tx-counts cannot be combined with weighted-split or auto-wallets")
+		}
+	}
+	if cfg.TxTemplate != nil {
+		if cfg.TxNumber != len(cfg.TxTemplate)*cfg.WalletsNumber {
+			problem("tx-template has %d transactions per wallet: txns must be %d", len(cfg.TxTemplate), len(cfg.TxTemplate)*cfg.WalletsNumber)
+		}
+		if cfg.SplitWeights != nil || cfg.WalletTxCounts != nil || cfg.AutoWallets || cfg.ValueDist != nil || cfg.TypedData != nil || cfg.Resume != nil {
+			problem("tx-template cannot be combined with weighted-split, wallet-tx-counts, auto-wallets, value-dist, typed-data or resume")
+		}
+	}
+	if cfg.Faucet != nil && (cfg.StreamWallets || cfg.AutoWallets) {
+		problem("faucet-url cannot be combined with stream-wallets or auto-wallets")
+	}
+	if cfg.FaucetWait < 0 {
+		problem("faucet-wait must be >= 0")
+	}
+	if cfg.AutoWalletsScan <= 0 {
+		problem("auto-wallets-scan must be > 0")
+	}
+	return errors.Join(problems...)
+}
+
+// validateRPCURL checks that rawURL is an http(s) or ws(s) URL, or an IPC socket path.
+func validateRPCURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid RPC URL %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+		if u.Host == "" {
+			return fmt.Errorf("invalid RPC URL %q: missing host", rawURL)
+		}
+	case "":
+		// go-ethereum treats scheme-less endpoints as IPC socket paths.
+	default:
+		return fmt.Errorf("invalid RPC URL %q: unsupported scheme %q", rawURL, u.Scheme)
+	}
+	return nil
+}
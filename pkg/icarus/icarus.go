@@ -0,0 +1,466 @@
+// Package icarus embeds the Icarus load generator in other Go programs: fill
+// in a Config, starting from DefaultConfig, and call Execute. Records are
+// logged the same way as by the command, to standard output unless the
+// program configures otherwise.
+package icarus
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	ethwallet "github.com/mdtosif/icarus/internal/account"
+	"github.com/mdtosif/icarus/internal/metrics"
+	"github.com/mdtosif/icarus/internal/rpc"
+	"github.com/mdtosif/icarus/internal/txmanager"
+)
+
+// Types that appear in Config and in the results.
+type (
+	// RunResult is the summary of a run: its counters and timing.
+	RunResult = txmanager.RunReport
+	// CostEstimate is the projected spend of a run (see EstimateCost).
+	CostEstimate = txmanager.CostEstimate
+	// MempoolProbe is the outcome of ProbeMempool.
+	MempoolProbe = txmanager.MempoolProbe
+	// WalletPending is one wallet's row of PendingTransactions.
+	WalletPending = txmanager.WalletPending
+	// SelfTestResult is the outcome of SelfTest.
+	SelfTestResult = txmanager.SelfTestResult
+
+	EndpointConfig    = rpc.EndpointConfig
+	EndpointShare     = txmanager.EndpointShare
+	Event             = txmanager.Event
+	GasOracle         = rpc.OracleClient
+	Faucet            = rpc.FaucetClient
+	FaucetRecord      = txmanager.FaucetRecord
+	Statsd            = metrics.Statsd
+	FeeOverride       = ethwallet.FeeOverride
+	FeeData           = ethwallet.FeeData
+	ValueDistribution = ethwallet.ValueDistribution
+	Checkpoint        = txmanager.Checkpoint
+	Prompter          = txmanager.Prompter
+	Clock             = txmanager.Clock
+	ManualClock       = txmanager.ManualClock
+	JitterMode        = txmanager.JitterMode
+	ErrorRecord       = txmanager.ErrorRecord
+	GasUsage          = txmanager.GasUsage
+	LatencyHistogram  = txmanager.LatencyHistogram
+	TypeCount         = txmanager.TypeCount
+	RevertRecord      = txmanager.RevertRecord
+	BroadcastOrder    = txmanager.BroadcastOrder
+	Signer            = ethwallet.Signer
+	TxSpec            = ethwallet.TxSpec
+	DerivationPath    = ethwallet.DerivationPath
+)
+
+// Retry jitter modes for Config.RetryJitter.
+const (
+	JitterNone  = txmanager.JitterNone
+	JitterFull  = txmanager.JitterFull
+	JitterEqual = txmanager.JitterEqual
+)
+
+// Broadcast orders for Config.BroadcastOrder.
+const (
+	OrderSequential = txmanager.OrderSequential
+	OrderShuffle    = txmanager.OrderShuffle
+	OrderInterleave = txmanager.OrderInterleave
+)
+
+// Kinds of Event sent to Config.Events.
+const (
+	EventStart     = txmanager.EventStart
+	EventBroadcast = txmanager.EventBroadcast
+	EventDone      = txmanager.EventDone
+)
+
+// Signers for Config.Signer.
+const (
+	SignerLondon    = ethwallet.SignerLondon
+	SignerEIP155    = ethwallet.SignerEIP155
+	SignerHomestead = ethwallet.SignerHomestead
+)
+
+// Config describes a run independently of command-line flags. DefaultConfig
+// holds the command's defaults; only RpcUrl and Mnemonic (or PrivateKey) must
+// be filled in, Validate checks the whole, and each field matches the flag of
+// the same purpose.
+type Config struct {
+	// RpcUrl is a comma-separated list of endpoints (or rpc "null" for a dry run).
+	RpcUrl        string
+	RpcClientName string
+	RpcTrace      bool
+	RpcEndpoints  map[string]EndpointConfig
+	// RpcWeights spreads broadcasts over the endpoints of RpcUrl at random in
+	// proportion to these weights, one per URL, instead of in turn.
+	RpcWeights []float64
+
+	Mnemonic      string
+	PrivateKey    string
+	WalletsNumber int
+	TxNumber      int
+	// Wait is the pause between broadcasts.
+	Wait          time.Duration
+	StreamWallets bool
+	SplitWeights  []float64
+
+	// Derivation selects the account and change levels of the path wallets
+	// are derived at, m/44'/60'/{Account}'/{Change}/{i}; zero is the default.
+	Derivation DerivationPath
+
+	// AutoWallets sends from the funded wallets among the first
+	// AutoWalletsScan only, instead of WalletsNumber wallets.
+	AutoWallets     bool
+	AutoWalletsScan int
+
+	// WalletTxCounts gives each wallet its own number of transactions;
+	// TxNumber is then their sum.
+	WalletTxCounts []int
+	// TxTemplate is the sequence of transactions every wallet sends, with
+	// their own recipient, value, calldata, gas limit and fees; TxNumber is
+	// then its length times WalletsNumber. See LoadTxTemplate.
+	TxTemplate []TxSpec
+
+	// GasMultiplier scales every gas estimate, rounded up, instead of adding
+	// the fixed buffer of 1000 gas; 0 keeps the buffer.
+	GasMultiplier float64
+
+	TipPercentile float64
+	TipBlocks     uint64
+	SharedFees    bool
+	ReplayFees    map[int]*FeeData
+	MaxFee        *big.Int
+	MaxGasPrice   *big.Int
+	MinTip        *big.Int
+	GasOracle     *GasOracle
+	FeeOverrides  map[int]FeeOverride
+	WalletLabels  map[int]string
+	ShowFees      bool
+
+	// ExplorerURL logs a link to every accepted transaction, its hash
+	// substituted for the %s of the template.
+	ExplorerURL string
+
+	// Faucet funds every wallet before the run, which waits up to FaucetWait
+	// for the funds to land.
+	Faucet     *Faucet
+	FaucetWait time.Duration
+
+	Value     *big.Int
+	To        string
+	ValueDist *ValueDistribution
+	Seed      uint64
+	DataSize  int
+	MaxSpend  *big.Int
+
+	// TagData appends the wallet index and nonce to every transaction's calldata.
+	TagData bool
+
+	RunTimeout          time.Duration
+	RequireFunded       bool
+	WaitReceipts        bool
+	ReceiptPollInterval time.Duration
+	ReceiptTimeout      time.Duration
+	ConfirmBlockTimeout uint64
+	EscalateAfter       time.Duration
+	VerifyMempool       bool
+	VerifyMempoolDelay  time.Duration
+	EscalatePercent     uint64
+
+	// NonceStallTimeout, when > 0, warns about wallets whose on-chain nonce
+	// has not moved for that long while they have transactions unmined.
+	NonceStallTimeout time.Duration
+
+	// Interrupt stops the run early when closed; receipts are still collected
+	// for ShutdownGrace.
+	Interrupt     <-chan struct{} `json:"-"`
+	ShutdownGrace time.Duration
+
+	Retries        int
+	RetryDelay     time.Duration
+	Retryable      func(error) bool `json:"-"`
+	RetryJitter    JitterMode
+	NonceRetries   int
+	CaptureErrors  int
+	ExpectDrain    bool
+	FailFast       bool
+	BroadcastToAll bool
+	BroadcastOrder BroadcastOrder
+	Signer         Signer
+	PerWalletTPS   float64
+	MaxInflight    int
+	Warmup         bool
+	Metrics        *Statsd
+
+	// AdaptiveErrorRate, when > 0, slows dispatching down while more than
+	// this share of the last AdaptiveWindow broadcasts fail (see -adaptive-error-rate).
+	AdaptiveErrorRate float64
+	AdaptiveWindow    int
+	AdaptiveMinTPS    float64
+	AdaptiveStepTPS   float64
+
+	// ConsecutiveFailureLimit, when > 0, aborts the run after that many
+	// failed broadcasts in a row.
+	ConsecutiveFailureLimit int
+
+	// Simulate skips the transactions an eth_call says would revert.
+	Simulate bool
+
+	TypedData *apitypes.TypedData
+	Verifier  *common.Address
+
+	BalanceBlock       *big.Int
+	BalanceConcurrency int
+	BalanceUnit        string
+
+	Label              string
+	ReportPath         string
+	DumpRawTxs         string
+	CheckpointPath     string
+	CheckpointInterval time.Duration
+	Resume             *Checkpoint
+	SummaryInterval    time.Duration
+	LogWorkerID        bool
+
+	// LatencyBuckets bounds the buckets of RunResult.Latency; LatencyCSV also
+	// writes them to a file.
+	LatencyBuckets []time.Duration
+	LatencyCSV     string
+
+	// AssumeYes skips the confirmation asked before sending on a mainnet;
+	// Prompt asks it, nil reading the answer from stdin.
+	AssumeYes bool
+	Prompt    Prompter `json:"-"`
+	Clock     Clock    `json:"-"`
+
+	// OnSigned, OnBroadcast and OnConfirmed follow every transaction through
+	// the run; they are called concurrently from the run's goroutines and
+	// should return quickly, as a slow hook delays its transaction.
+	OnSigned    func(tx *types.Transaction)       `json:"-"`
+	OnBroadcast func(hash common.Hash, err error) `json:"-"`
+	OnConfirmed func(receipt *types.Receipt)      `json:"-"`
+	// Events receives the planned transactions of each wallet, then the
+	// outcome of every broadcast; broadcasts wait for it to be read.
+	Events chan<- Event `json:"-"`
+}
+
+// Redacted returns a copy of cfg safe to print or store: the mnemonic is
+// replaced by its word count and the private key by a placeholder. Encoded
+// as JSON it records how a run was parameterized; hooks, the clock and the
+// interrupt channel are left out.
+func (cfg Config) Redacted() Config {
+	if cfg.Mnemonic != "" {
+		cfg.Mnemonic = fmt.Sprintf("<redacted, %d words>", len(strings.Fields(cfg.Mnemonic)))
+	}
+	if cfg.PrivateKey != "" {
+		cfg.PrivateKey = "<redacted>"
+	}
+	return cfg
+}
+
+// Execute runs the load test described by cfg and returns its summary. The
+// result holds the counters gathered so far even when err is not nil.
+func Execute(cfg Config) (RunResult, error) {
+	t := cfg.manager()
+	err := t.Run()
+	return t.Report(), err
+}
+
+// EstimateCost projects the gas and value a run of cfg would spend at current
+// fees, without sending anything.
+func EstimateCost(cfg Config) (CostEstimate, error) {
+	return cfg.manager().EstimateCost()
+}
+
+// ProbeMempool sends up to max transactions from cfg's first wallet to find
+// the node's per-account pending limit.
+func ProbeMempool(cfg Config, max int) (MempoolProbe, error) {
+	return cfg.manager().ProbeMempool(max)
+}
+
+// PendingTransactions reports how many transactions each of cfg's wallets
+// still has unmined in the node's mempool.
+func PendingTransactions(cfg Config) ([]WalletPending, error) {
+	return cfg.manager().PendingTransactions()
+}
+
+// SelfTest signs a known transaction with cfg's first wallet and Signer and
+// checks, offline, that its sender recovers to the wallet.
+func SelfTest(cfg Config) (SelfTestResult, error) {
+	return cfg.manager().SelfTest()
+}
+
+// NewGasOracle returns a client for the HTTP gas oracle at url, for
+// Config.GasOracle; timeout <= 0 uses the default.
+func NewGasOracle(url string, timeout time.Duration) *GasOracle {
+	return rpc.NewOracleClient(url, timeout)
+}
+
+// DialStatsd returns a client sending the run's metrics, prefixed by prefix,
+// to the StatsD server at addr, for Config.Metrics.
+func DialStatsd(addr, prefix string) (*Statsd, error) {
+	return metrics.DialStatsd(addr, prefix)
+}
+
+// NewFaucet returns a client for the HTTP faucet at url, for Config.Faucet,
+// sending at most rps requests per second; rps and timeout <= 0 use the
+// defaults.
+func NewFaucet(url string, rps float64, timeout time.Duration) *Faucet {
+	return rpc.NewFaucetClient(url, rps, timeout)
+}
+
+// NewManualClock returns a Clock that only moves when advanced, for tests
+// of code that drives a run through Config.Clock.
+func NewManualClock(start time.Time) *ManualClock {
+	return txmanager.NewManualClock(start)
+}
+
+// LoadCheckpoint reads a checkpoint written by an earlier run, for Config.Resume.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	return txmanager.LoadCheckpoint(path)
+}
+
+// LoadReplayFees reads the fees each wallet used from the JSON report of an
+// earlier run, for Config.ReplayFees.
+func LoadReplayFees(path string) (map[int]*FeeData, error) {
+	return txmanager.LoadReplayFees(path)
+}
+
+// LoadTxTemplate reads a JSON array of transactions, for Config.TxTemplate.
+func LoadTxTemplate(path string) ([]TxSpec, error) {
+	return txmanager.LoadTxTemplate(path)
+}
+
+// SetNativeDecimals sets, for the whole process, the decimals of the chain's
+// native token used to read and print Ether amounts (18 by default).
+func SetNativeDecimals(decimals int) error {
+	return ethwallet.SetNativeDecimals(decimals)
+}
+
+// ParseValueDistribution parses "uniform:min,max", "normal:mean,stddev" or
+// "exponential:mean" (in Ether), for Config.ValueDist.
+func ParseValueDistribution(spec string) (*ValueDistribution, error) {
+	return ethwallet.ParseValueDistribution(spec)
+}
+
+// manager builds the transaction manager that carries out a run of cfg.
+func (cfg Config) manager() *txmanager.TxManager {
+	return &txmanager.TxManager{
+		RpcUrl:        cfg.RpcUrl,
+		RpcClientName: cfg.RpcClientName,
+		RpcTrace:      cfg.RpcTrace,
+		RpcEndpoints:  cfg.RpcEndpoints,
+		RpcWeights:    cfg.RpcWeights,
+		WaitMilis:     int(cfg.Wait / time.Millisecond),
+		WalletsNumber: cfg.WalletsNumber,
+		TxNumber:      cfg.TxNumber,
+		Mu:            &sync.Mutex{},
+		Mnemonic:      cfg.Mnemonic,
+		PrivateKey:    cfg.PrivateKey,
+		Derivation:    cfg.Derivation,
+		TipPercentile: cfg.TipPercentile,
+		TipBlocks:     cfg.TipBlocks,
+		RunTimeout:    cfg.RunTimeout,
+		Interrupt:     cfg.Interrupt,
+		ShutdownGrace: cfg.ShutdownGrace,
+		RequireFunded: cfg.RequireFunded,
+		StreamWallets: cfg.StreamWallets,
+
+		AutoWallets:     cfg.AutoWallets,
+		AutoWalletsScan: cfg.AutoWalletsScan,
+		WalletTxCounts:  cfg.WalletTxCounts,
+		TxTemplate:      cfg.TxTemplate,
+
+		GasMultiplier: cfg.GasMultiplier,
+
+		WaitReceipts:        cfg.WaitReceipts,
+		ReceiptPollInterval: cfg.ReceiptPollInterval,
+		ReceiptTimeout:      cfg.ReceiptTimeout,
+		ConfirmBlockTimeout: cfg.ConfirmBlockTimeout,
+		EscalateAfter:       cfg.EscalateAfter,
+		VerifyMempool:       cfg.VerifyMempool,
+		VerifyMempoolDelay:  cfg.VerifyMempoolDelay,
+		EscalatePercent:     cfg.EscalatePercent,
+		Clock:               cfg.Clock,
+		NonceStallTimeout:   cfg.NonceStallTimeout,
+
+		Label:          cfg.Label,
+		ReportPath:     cfg.ReportPath,
+		LatencyBuckets: cfg.LatencyBuckets,
+		LatencyCSV:     cfg.LatencyCSV,
+
+		SplitWeights: cfg.SplitWeights,
+		SharedFees:   cfg.SharedFees,
+		ReplayFees:   cfg.ReplayFees,
+		Value:        cfg.Value,
+		To:           cfg.To,
+		ValueDist:    cfg.ValueDist,
+		Seed:         cfg.Seed,
+		DataSize:     cfg.DataSize,
+		TagData:      cfg.TagData,
+		MaxFee:       cfg.MaxFee,
+		MaxGasPrice:  cfg.MaxGasPrice,
+		MinTip:       cfg.MinTip,
+		GasOracle:    cfg.GasOracle,
+		Faucet:       cfg.Faucet,
+		FaucetWait:   cfg.FaucetWait,
+		MaxSpend:     cfg.MaxSpend,
+		FeeOverrides: cfg.FeeOverrides,
+		WalletLabels: cfg.WalletLabels,
+		ShowFees:     cfg.ShowFees,
+		ExplorerURL:  cfg.ExplorerURL,
+		BalanceBlock: cfg.BalanceBlock,
+
+		BalanceConcurrency: cfg.BalanceConcurrency,
+		BalanceUnit:        cfg.BalanceUnit,
+		ExpectDrain:        cfg.ExpectDrain,
+		BroadcastToAll:     cfg.BroadcastToAll,
+		BroadcastOrder:     cfg.BroadcastOrder,
+		Signer:             cfg.Signer,
+		PerWalletTPS:       cfg.PerWalletTPS,
+		MaxInflight:        cfg.MaxInflight,
+		Metrics:            cfg.Metrics,
+		FailFast:           cfg.FailFast,
+
+		AdaptiveErrorRate: cfg.AdaptiveErrorRate,
+		AdaptiveWindow:    cfg.AdaptiveWindow,
+		AdaptiveMinTPS:    cfg.AdaptiveMinTPS,
+		AdaptiveStepTPS:   cfg.AdaptiveStepTPS,
+
+		ConsecutiveFailureLimit: cfg.ConsecutiveFailureLimit,
+		Simulate:                cfg.Simulate,
+
+		TypedData: cfg.TypedData,
+		Verifier:  cfg.Verifier,
+		AssumeYes: cfg.AssumeYes,
+		Prompt:    cfg.Prompt,
+
+		OnSigned:    cfg.OnSigned,
+		OnBroadcast: cfg.OnBroadcast,
+		OnConfirmed: cfg.OnConfirmed,
+		Events:      cfg.Events,
+
+		Retries:     cfg.Retries,
+		RetryDelay:  cfg.RetryDelay,
+		Retryable:   cfg.Retryable,
+		RetryJitter: cfg.RetryJitter,
+
+		NonceRetries: cfg.NonceRetries,
+
+		CaptureErrors: cfg.CaptureErrors,
+		Warmup:        cfg.Warmup,
+		LogWorkerID:   cfg.LogWorkerID,
+
+		CheckpointPath:     cfg.CheckpointPath,
+		CheckpointInterval: cfg.CheckpointInterval,
+		Resume:             cfg.Resume,
+		SummaryInterval:    cfg.SummaryInterval,
+		DumpRawTxs:         cfg.DumpRawTxs,
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mdtosif/icarus/internal/logger"
+	"github.com/mdtosif/icarus/pkg/icarus"
+	"golang.org/x/term"
+)
+
+const (
+	// dashboardRefresh is how often the dashboard is redrawn.
+	dashboardRefresh = 250 * time.Millisecond
+	// dashboardErrors is how many recent errors the dashboard lists.
+	dashboardErrors = 5
+)
+
+// dashboard is the -tui display: a full-screen view of the broadcasts,
+// redrawn from the run's events, with a progress bar per wallet, the
+// aggregate rate, the success and failure counts and the last errors. It
+// takes over the terminal from EventStart to EventDone; in between the
+// console logs are suspended, while -log-file keeps receiving them.
+type dashboard struct {
+	out    *os.File
+	events chan icarus.Event
+
+	mu       sync.Mutex
+	active   bool
+	started  time.Time
+	planned  []int
+	sent     []int
+	names    []string
+	success  int
+	failed   int
+	errors   []string
+	resume   func()
+	stopDraw chan struct{}
+	drawn    chan struct{}
+
+	// lastSettled and lastDraw give the rate since the previous frame.
+	lastSettled int
+	lastDraw    time.Time
+	rate        float64
+}
+
+// newDashboard returns a dashboard drawing to out, or nil when out is not a
+// terminal.
+func newDashboard(out *os.File) *dashboard {
+	if !term.IsTerminal(int(out.Fd())) {
+		return nil
+	}
+	d := &dashboard{out: out, events: make(chan icarus.Event, 1024)}
+	go d.consume()
+	return d
+}
+
+// consume applies the events for as long as the process runs; broadcasts
+// left behind by a timeout may still send some after EventDone.
+func (d *dashboard) consume() {
+	for e := range d.events {
+		switch e.Kind {
+		case icarus.EventStart:
+			d.start(e.Planned)
+		case icarus.EventBroadcast:
+			d.record(e)
+		case icarus.EventDone:
+			d.Stop()
+		}
+	}
+}
+
+func (d *dashboard) start(planned []int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.active {
+		return
+	}
+	d.active = true
+	d.started = time.Now()
+	d.lastDraw = d.started
+	d.planned = planned
+	d.sent = make([]int, len(planned))
+	d.names = make([]string, len(planned))
+	d.resume = logger.SuspendConsole()
+	d.stopDraw = make(chan struct{})
+	d.drawn = make(chan struct{})
+	fmt.Fprint(d.out, "\x1b[?25l")
+
+	go func() {
+		defer close(d.drawn)
+		ticker := time.NewTicker(dashboardRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stopDraw:
+				return
+			case <-ticker.C:
+				d.draw()
+			}
+		}
+	}()
+}
+
+func (d *dashboard) record(e icarus.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.active || e.Wallet >= len(d.sent) {
+		return
+	}
+	d.sent[e.Wallet]++
+	d.names[e.Wallet] = e.Name
+	if e.Err == nil {
+		d.success++
+		return
+	}
+	d.failed++
+	d.errors = append(d.errors, fmt.Sprintf("%s %s: %v", time.Now().Format("15:04:05"), e.Name, e.Err))
+	if len(d.errors) > dashboardErrors {
+		d.errors = d.errors[len(d.errors)-dashboardErrors:]
+	}
+}
+
+// Stop draws the last frame, leaves it on screen and gives the terminal
+// back to the logs. It is safe to call more than once and before EventStart.
+func (d *dashboard) Stop() {
+	d.mu.Lock()
+	if !d.active {
+		d.mu.Unlock()
+		return
+	}
+	d.active = false
+	d.mu.Unlock()
+
+	close(d.stopDraw)
+	<-d.drawn
+	d.draw()
+	fmt.Fprint(d.out, "\x1b[?25h")
+	d.resume()
+}
+
+// draw redraws the whole screen.
+func (d *dashboard) draw() {
+	width, height, err := term.GetSize(int(d.out.Fd()))
+	if err != nil || width < 40 {
+		width, height = 80, 24
+	}
+
+	d.mu.Lock()
+	now := time.Now()
+	elapsed := now.Sub(d.started)
+	settled := d.success + d.failed
+	if dt := now.Sub(d.lastDraw).Seconds(); dt > 0 {
+		d.rate = float64(settled-d.lastSettled) / dt
+	}
+	d.lastSettled, d.lastDraw = settled, now
+	total := 0
+	for _, n := range d.planned {
+		total += n
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	var average float64
+	if elapsed > 0 {
+		average = float64(settled) / elapsed.Seconds()
+	}
+	fmt.Fprintf(&b, "icarus  %v elapsed  %.1f tx/s now, %.1f tx/s average\n", elapsed.Round(time.Second), d.rate, average)
+	fmt.Fprintf(&b, "sent %d/%d  success %d  failed %d\n\n", settled, total, d.success, d.failed)
+
+	// Wallets get the rows the header and the errors leave free.
+	rows := height - 4 - 2 - dashboardErrors
+	barWidth := max(width-40, 10)
+	for i, planned := range d.planned {
+		if i >= rows-1 && len(d.planned) > rows {
+			fmt.Fprintf(&b, "... %d more wallets\n", len(d.planned)-i)
+			break
+		}
+		name := d.names[i]
+		if name == "" {
+			name = fmt.Sprintf("wallet %d", i)
+		}
+		fmt.Fprintf(&b, "%-16.16s %s %d/%d\n", name, progressBar(d.sent[i], planned, barWidth), d.sent[i], planned)
+	}
+
+	b.WriteString("\nRecent errors:\n")
+	if len(d.errors) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, msg := range d.errors {
+		if len(msg) > width-2 {
+			msg = msg[:width-5] + "..."
+		}
+		fmt.Fprintf(&b, "  %s\n", msg)
+	}
+	d.mu.Unlock()
+
+	d.out.WriteString(b.String())
+}
+
+// progressBar renders done out of total as a bar width characters wide.
+func progressBar(done, total, width int) string {
+	filled := width
+	if total > 0 {
+		filled = min(done*width/total, width)
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(".", width-filled) + "]"
+}